@@ -14,14 +14,20 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
+	"encoding/json"
 	"flag"
-	"fmt"
 	"io"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 
+	"emperror.dev/errors"
 	"github.com/je4/utils/v2/pkg/zLogger"
 	"github.com/ocfl-archive/indexer/v3/pkg/indexer"
 	"github.com/ocfl-archive/indexer/v3/pkg/util"
@@ -33,6 +39,26 @@ const INDEXER = "indexer v0.2, info-age GmbH Basel"
 
 var configFile = flag.String("cfg", "", "config file location")
 var inputFile = flag.String("in", "", "input file location")
+var inputDir = flag.String("dir", "", "input folder for batch indexing")
+var outputFile = flag.String("out", "", "jsonl output file for batch indexing, files with a result are skipped, errors and, without -head, partial results are indexed again (default stdout)")
+var includeGlobs = flag.String("include", "", "comma separated globs of files to index")
+var excludeGlobs = flag.String("exclude", "", "comma separated globs of files and folders to skip")
+var maxSize = flag.Int64("maxsize", 0, "max. file size for batch indexing, 0 for no limit")
+var workers = flag.Int("workers", runtime.NumCPU(), "number of parallel workers for batch indexing")
+var actionList = flag.String("actions", "", "comma separated list of actions (default all)")
+var headOnly = flag.Bool("head", false, "index only the head of the files with the identification actions, the results are marked as partial")
+var premisOutput = flag.Bool("premis", false, "write the result of a single file as PREMIS object")
+var metsFile = flag.String("mets", "", "write the batch results of -out as METS document to this file, indexes -dir first if given")
+
+func splitList(list string) []string {
+	var result []string
+	for _, s := range strings.Split(list, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
 
 func main() {
 	var err error
@@ -94,6 +120,14 @@ func main() {
 	l2 := _logger.With().Timestamp().Str("host", hostname).Logger() //.Output(output)
 	var logger zLogger.ZLogger = &l2
 
+	// without input folder, the existing batch output is converted
+	if *metsFile != "" && *inputDir == "" {
+		if err := writeMETS(); err != nil {
+			logger.Fatal().Err(err).Msgf("cannot write %s", *metsFile)
+		}
+		return
+	}
+
 	ad, actions, closer, err := util.InitIndexer(conf.Indexer, logger)
 	if err != nil {
 		log.Fatalf("Error initializing indexer: %v", err)
@@ -105,6 +139,35 @@ func main() {
 		}
 	}(closer)
 
+	if handler := ad.MetricsHandler(); handler != nil && conf.Indexer.Metrics.Addr != "" {
+		metricsServer, err := util.ServeMetrics(conf.Indexer.Metrics.Addr, handler, logger)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("cannot start metrics endpoint")
+		}
+		defer metricsServer.Close()
+	}
+
+	if requested := splitList(*actionList); len(requested) > 0 {
+		for _, name := range requested {
+			if !slices.Contains(actions, name) {
+				logger.Fatal().Msgf("action '%s' not available", name)
+			}
+		}
+		actions = requested
+	}
+
+	if *inputDir != "" {
+		if err := batch(ad.ActionDispatcher(), actions, logger); err != nil {
+			logger.Fatal().Err(err).Msgf("cannot index %s", *inputDir)
+		}
+		if *metsFile != "" {
+			if err := writeMETS(); err != nil {
+				logger.Fatal().Err(err).Msgf("cannot write %s", *metsFile)
+			}
+		}
+		return
+	}
+
 	fp, err := os.Open(*inputFile)
 	if err != nil {
 		logger.Fatal().Msgf("cannot open input file: %v", err)
@@ -116,10 +179,100 @@ func main() {
 		}
 	}(fp)
 
-	result, err := ad.ActionDispatcher().Stream(fp, []string{filepath.Base(*inputFile)}, actions)
+	var result *indexer.ResultV2
+	if *headOnly {
+		var size int64 = -1
+		if info, err := fp.Stat(); err == nil {
+			size = info.Size()
+		}
+		result, err = ad.ActionDispatcher().HeadContext(context.Background(), fp, size, []string{filepath.Base(*inputFile)}, actions)
+	} else {
+		result, err = ad.ActionDispatcher().Stream(fp, []string{filepath.Base(*inputFile)}, actions)
+	}
 	if err != nil {
 		logger.Error().Msgf("error streaming file: %v", err)
 		return
 	}
-	fmt.Println(result)
+	if *premisOutput {
+		premis, err := indexer.NewPREMIS(result, filepath.Base(*inputFile), filepath.Base(*inputFile), util.ToolVersions(conf.Indexer))
+		if err != nil {
+			logger.Error().Msgf("cannot create premis: %v", err)
+			return
+		}
+		if err := premis.Write(os.Stdout); err != nil {
+			logger.Error().Msgf("cannot write result: %v", err)
+		}
+		return
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(result); err != nil {
+		logger.Error().Msgf("cannot write result: %v", err)
+	}
+}
+
+// batch indexes all files of inputDir and appends the results as jsonl to outputFile
+func batch(ad *indexer.ActionDispatcher, actions []string, logger zLogger.ZLogger) error {
+	b, err := indexer.NewBatch(ad, actions, splitList(*includeGlobs), splitList(*excludeGlobs), *maxSize, *workers, *headOnly)
+	if err != nil {
+		return err
+	}
+	var out io.Writer = os.Stdout
+	var done = map[string]bool{}
+	if *outputFile != "" {
+		fp, err := os.OpenFile(*outputFile, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		defer fp.Close()
+		if done, err = indexer.ReadBatchDone(fp, *headOnly); err != nil {
+			return err
+		}
+		// terminate an incomplete last line of an interrupted run
+		if info, err := fp.Stat(); err == nil && info.Size() > 0 {
+			last := make([]byte, 1)
+			if _, err := fp.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+				if _, err := fp.Write([]byte{'\n'}); err != nil {
+					return err
+				}
+			}
+		}
+		if len(done) > 0 {
+			logger.Info().Msgf("skipping %d files already indexed in %s", len(done), *outputFile)
+		}
+		out = fp
+	}
+	// abort on ctrl-c, the output can be resumed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return b.Run(ctx, os.DirFS(*inputDir), ".", out, done)
+}
+
+// writeMETS writes the results of outputFile as METS document to metsFile
+func writeMETS() error {
+	if *outputFile == "" {
+		return errors.New("mets output needs the batch output file -out")
+	}
+	fp, err := os.Open(*outputFile)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	results, err := indexer.ReadBatchResults(fp)
+	if err != nil {
+		return err
+	}
+	mets, err := indexer.NewMETS(filepath.Base(*inputDir), results)
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(*metsFile)
+	if err != nil {
+		return err
+	}
+	if err := mets.Write(out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
 }
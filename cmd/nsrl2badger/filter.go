@@ -0,0 +1,73 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"emperror.dev/errors"
+	badger "github.com/dgraph-io/badger/v4"
+	"github.com/ocfl-archive/indexer/v3/pkg/indexer"
+)
+
+func iterateFileKeys(db *badger.DB, fn func(key string)) error {
+	return db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = []byte(indexer.NSRL_File)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			fn(string(it.Item().Key()))
+		}
+		return nil
+	})
+}
+
+// buildFilter writes a bloom filter of all SHA-1 file keys of the badger database
+func buildFilter(badgerFolder, filterFile string, fp float64) error {
+	bconfig := badger.DefaultOptions(badgerFolder)
+	bconfig.ReadOnly = true
+	db, err := badger.Open(bconfig)
+	if err != nil {
+		return errors.Wrapf(err, "cannot open badger database")
+	}
+	defer db.Close()
+
+	var count uint64
+	if err := iterateFileKeys(db, func(key string) { count++ }); err != nil {
+		return errors.Wrap(err, "cannot count file keys")
+	}
+	fmt.Printf("building filter for %v keys\n", count)
+	filter := indexer.NewNSRLFilter(count, fp)
+	if err := iterateFileKeys(db, filter.Add); err != nil {
+		return errors.Wrap(err, "cannot read file keys")
+	}
+
+	fpw, err := os.Create(filterFile)
+	if err != nil {
+		return errors.Wrapf(err, "cannot create %s", filterFile)
+	}
+	size, err := filter.WriteTo(fpw)
+	if err != nil {
+		fpw.Close()
+		return errors.Wrapf(err, "cannot write %s", filterFile)
+	}
+	if err := fpw.Close(); err != nil {
+		return errors.Wrapf(err, "cannot close %s", filterFile)
+	}
+	fmt.Printf("filter %s: %v keys, %v bytes\n", filterFile, filter.Count(), size)
+	return nil
+}
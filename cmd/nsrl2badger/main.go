@@ -238,7 +238,8 @@ func isoZip(isoFile, badgerFolder, fileFile, osFile, mfgFile, prodFile, checksum
 			for _, fi := range zipR.File {
 				fr, err := fi.Open()
 				if err != nil {
-					fmt.Printf("cannot open zip content %s/%s")
+					fmt.Printf("cannot open zip content %s/%s\n", f.Name(), fi.Name)
+					continue
 				}
 				if err := copyCSV(strings.ToUpper(checksum), db, fr, []int{0, 1, 2}, checkonly); err != nil {
 					return errors.Wrapf(err, "cannot copy file csv %s", fi.Name)
@@ -380,24 +381,44 @@ func main() {
 	checkSum := flag.String("checksum", "MD5", "MD5 OR SHA-1 as key value")
 	noFile := flag.Bool("nofile", false, "ignore nsrlfile.zip if true")
 	checkOnly := flag.Bool("checkonly", false, "true, of only one entry per checksum is needed")
+	rds3Files := flag.String("rds3", "", "comma separated list of NSRL RDS v3 SQLite databases (use with -checksum SHA-1)")
+	previous := flag.String("previous", "", "previous RDS v3 release, only the differences to -rds3 or -delta are imported")
+	deltaFile := flag.String("delta", "", "RDS v3 delta SQL script, applied to a copy of -previous")
+	filterFile := flag.String("filter", "", "write a bloom filter of the SHA-1 keys of the badger database to this file")
+	filterFP := flag.Float64("filterfp", 0.001, "false positive rate of the bloom filter")
 
 	flag.Parse()
 
 	*fileFile = strings.ToLower(*fileFile)
 
-	if *zipFile != "" {
+	if *rds3Files != "" || *deltaFile != "" {
+		var files []string
+		for _, f := range strings.Split(*rds3Files, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				files = append(files, f)
+			}
+		}
+		if err := rds3(files, *previous, *deltaFile, *badgerFolder, *checkSum); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	} else if *zipFile != "" {
 		if *fileFile == "" {
 			*fileFile = "/nsrlfile.txt"
 		}
 		if err := zipTxt(*zipFile, *badgerFolder, *fileFile, *osFile, *mfgFile, *prodFile, *checkSum, *noFile, *checkOnly); err != nil {
-			fmt.Sprintf("Error: %v", err)
+			fmt.Printf("Error: %v\n", err)
 		}
 	} else if *isoFile != "" {
 		if *fileFile == "" {
 			*fileFile = "/nsrlfile.zip"
 		}
 		if err := isoZip(*isoFile, *badgerFolder, *fileFile, *osFile, *mfgFile, *prodFile, strings.ToUpper(*checkSum), *noFile, *checkOnly); err != nil {
-			fmt.Sprintf("Error: %v", err)
+			fmt.Printf("Error: %v\n", err)
+		}
+	}
+	if *filterFile != "" {
+		if err := buildFilter(*badgerFolder, *filterFile, *filterFP); err != nil {
+			fmt.Printf("Error: %v\n", err)
 		}
 	}
 }
@@ -0,0 +1,355 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"emperror.dev/errors"
+	badger "github.com/dgraph-io/badger/v4"
+	"github.com/golang/snappy"
+	_ "modernc.org/sqlite"
+)
+
+// RDS v3 is distributed as SQLite databases with the tables FILE, PKG, OS and MFG.
+// The entries are stored with the keys and field names of the legacy RDS 2.x text files,
+// so that the indexer can handle databases of both versions.
+
+const rds3BatchSize = 1000
+
+type rds3Table struct {
+	table string
+	// first column is the key
+	columns   []string
+	keyPrefix string
+	// legacy field names of the other columns
+	fields []string
+}
+
+func (t rds3Table) selectFrom(schema string) string {
+	return fmt.Sprintf("SELECT %s FROM %s.%s", strings.Join(t.columns, ", "), schema, t.table)
+}
+
+func rds3Tables(checksum string) ([]rds3Table, error) {
+	var keyColumn string
+	switch strings.ToUpper(checksum) {
+	case "SHA-1", "":
+		checksum = "SHA-1"
+		keyColumn = "sha1"
+	case "MD5":
+		keyColumn = "md5"
+	default:
+		return nil, fmt.Errorf("invalid checksum type: %s", checksum)
+	}
+	return []rds3Table{
+		{
+			table:     "MFG",
+			columns:   []string{"manufacturer_id", "name"},
+			keyPrefix: "MfgCode-",
+			fields:    []string{"MfgName"},
+		},
+		{
+			table:     "OS",
+			columns:   []string{"operating_system_id", "name", "version", "manufacturer_id"},
+			keyPrefix: "OpSystemCode-",
+			fields:    []string{"OpSystemName", "OpSystemVersion", "MfgCode"},
+		},
+		{
+			table:     "PKG",
+			columns:   []string{"package_id", "name", "version", "operating_system_id", "manufacturer_id", "language", "application_type"},
+			keyPrefix: "ProductCode-",
+			fields:    []string{"ProductName", "ProductVersion", "OpSystemCode", "MfgCode", "Language", "ApplicationType"},
+		},
+		{
+			table:     "FILE",
+			columns:   []string{keyColumn, "file_name", "file_size", "package_id"},
+			keyPrefix: strings.ToUpper(checksum) + "-",
+			fields:    []string{"FileName", "FileSize", "ProductCode"},
+		},
+	}, nil
+}
+
+func openRDS3(filename string, readOnly bool) (*sql.DB, error) {
+	if _, err := os.Stat(filename); err != nil {
+		return nil, errors.Wrapf(err, "cannot stat rds database %s", filename)
+	}
+	dsn := "file:" + filename
+	if readOnly {
+		dsn += "?mode=ro"
+	}
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open rds database %s", filename)
+	}
+	// needed for attached databases
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+func removeEntries(ls []map[string]string, els ...map[string]string) []map[string]string {
+	var result []map[string]string
+	for _, l := range ls {
+		found := false
+		for _, el := range els {
+			if len(el) != len(l) {
+				continue
+			}
+			same := true
+			for key, val := range el {
+				if l[key] != val {
+					same = false
+					break
+				}
+			}
+			if same {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+func flushRDS3(db *badger.DB, batch map[string][]map[string]string, remove bool) error {
+	if err := db.Update(func(txn *badger.Txn) error {
+		for key, list := range batch {
+			var existing []map[string]string
+			item, err := txn.Get([]byte(key))
+			if err != nil {
+				if err != badger.ErrKeyNotFound {
+					return errors.Wrapf(err, "cannot get key %s", key)
+				}
+			} else {
+				if err := item.Value(func(val []byte) error {
+					dec, err := snappy.Decode(nil, val)
+					if err != nil {
+						return errors.Wrapf(err, "cannot decode %s", key)
+					}
+					if err := json.Unmarshal(dec, &existing); err != nil {
+						return errors.Wrapf(err, "cannot unmarshal %s", string(dec))
+					}
+					return nil
+				}); err != nil {
+					return errors.Wrapf(err, "cannot get value of %s", key)
+				}
+			}
+			if remove {
+				existing = removeEntries(existing, list...)
+				if len(existing) == 0 {
+					if err := txn.Delete([]byte(key)); err != nil {
+						return errors.Wrapf(err, "cannot delete %s", key)
+					}
+					continue
+				}
+			} else {
+				existing = appendIfNotExists(existing, list...)
+			}
+			d, err := json.Marshal(existing)
+			if err != nil {
+				return errors.Wrapf(err, "cannot marshal data %v", existing)
+			}
+			if err := txn.Set([]byte(key), snappy.Encode(nil, d)); err != nil {
+				return errors.Wrapf(err, "cannot store struct %v", string(d))
+			}
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "cannot store rds data")
+	}
+	return nil
+}
+
+// copyRDS3 writes the rows of the query to badger. The rows must be ordered by the key column.
+// If remove is true, the rows are removed from the existing entries.
+func copyRDS3(db *badger.DB, rdsDB *sql.DB, query string, t rds3Table, remove bool) error {
+	defer fmt.Println()
+	rows, err := rdsDB.Query(query)
+	if err != nil {
+		return errors.Wrapf(err, "cannot query '%s'", query)
+	}
+	defer rows.Close()
+
+	var counter int64
+	var batch = map[string][]map[string]string{}
+	var values = make([]sql.NullString, len(t.columns))
+	var dest = make([]any, len(t.columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	var currentKey string
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return errors.Wrapf(err, "cannot scan row of %s", t.table)
+		}
+		key := t.keyPrefix + strings.ToUpper(values[0].String)
+		if key != currentKey && len(batch) >= rds3BatchSize {
+			if err := flushRDS3(db, batch, remove); err != nil {
+				return err
+			}
+			batch = map[string][]map[string]string{}
+		}
+		currentKey = key
+		dataStruct := make(map[string]string)
+		for i, name := range t.fields {
+			dataStruct[name] = values[i+1].String
+		}
+		batch[key] = appendIfNotExists(batch[key], dataStruct)
+		counter++
+		if counter%1000 == 0 {
+			fmt.Printf("%s %v: %s    \r", t.table, counter, key)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Wrapf(err, "cannot read rows of %s", t.table)
+	}
+	if len(batch) > 0 {
+		if err := flushRDS3(db, batch, remove); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("%s %v rows", t.table, counter)
+	return nil
+}
+
+// applyDelta applies the delta sql script to a temporary copy of the previous release
+func applyDelta(previous, deltaFile string) (string, error) {
+	src, err := os.Open(previous)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot open %s", previous)
+	}
+	defer src.Close()
+	tmp, err := os.CreateTemp("", "nsrl-rds3-*.db")
+	if err != nil {
+		return "", errors.Wrap(err, "cannot create temporary database")
+	}
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", errors.Wrapf(err, "cannot copy %s to %s", previous, tmp.Name())
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", errors.Wrapf(err, "cannot close %s", tmp.Name())
+	}
+	script, err := os.ReadFile(deltaFile)
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", errors.Wrapf(err, "cannot read delta %s", deltaFile)
+	}
+	db, err := openRDS3(tmp.Name(), false)
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	defer db.Close()
+	fmt.Printf("applying delta %s to copy of %s\n", deltaFile, previous)
+	if _, err := db.Exec(string(script)); err != nil {
+		os.Remove(tmp.Name())
+		return "", errors.Wrapf(err, "cannot apply delta %s", deltaFile)
+	}
+	return tmp.Name(), nil
+}
+
+// rds3 imports NSRL RDS v3 SQLite databases.
+// Without previous, all entries of the databases are added.
+// With previous, only the differences between previous and the new release (rdsFiles[0] or previous + delta) are applied.
+func rds3(rdsFiles []string, previous, deltaFile, badgerFolder, checksum string) error {
+	tables, err := rds3Tables(checksum)
+	if err != nil {
+		return err
+	}
+
+	stat2, err := os.Stat(badgerFolder)
+	if err != nil {
+		return errors.Wrapf(err, "cannot stat badger folder %s", badgerFolder)
+	}
+	if !stat2.IsDir() {
+		return fmt.Errorf("%s is not a directory", badgerFolder)
+	}
+
+	bconfig := badger.DefaultOptions(badgerFolder)
+	db, err := badger.Open(bconfig)
+	if err != nil {
+		return errors.Wrapf(err, "cannot open badger database")
+	}
+	defer db.Close()
+
+	if previous == "" {
+		if deltaFile != "" {
+			return errors.New("delta needs the previous release")
+		}
+		for _, rdsFile := range rdsFiles {
+			fmt.Printf("importing %s\n", rdsFile)
+			rdsDB, err := openRDS3(rdsFile, true)
+			if err != nil {
+				return err
+			}
+			for _, t := range tables {
+				if err := copyRDS3(db, rdsDB, t.selectFrom("main")+" ORDER BY 1", t, false); err != nil {
+					rdsDB.Close()
+					return errors.Wrapf(err, "cannot import %s from %s", t.table, rdsFile)
+				}
+			}
+			rdsDB.Close()
+		}
+		return nil
+	}
+
+	var current string
+	switch {
+	case deltaFile != "":
+		if len(rdsFiles) != 0 {
+			return errors.New("use either rds3 or delta with previous")
+		}
+		current, err = applyDelta(previous, deltaFile)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(current)
+	case len(rdsFiles) == 1:
+		current = rdsFiles[0]
+	default:
+		return errors.New("incremental import needs exactly one rds3 database")
+	}
+
+	fmt.Printf("importing differences between %s and %s\n", previous, current)
+	rdsDB, err := openRDS3(current, true)
+	if err != nil {
+		return err
+	}
+	defer rdsDB.Close()
+	if _, err := rdsDB.Exec("ATTACH DATABASE ? AS prev", "file:"+previous+"?mode=ro"); err != nil {
+		return errors.Wrapf(err, "cannot attach %s", previous)
+	}
+	for _, t := range tables {
+		// remove first, changed entries are added again
+		removed := fmt.Sprintf("SELECT * FROM (%s EXCEPT %s) ORDER BY 1", t.selectFrom("prev"), t.selectFrom("main"))
+		if err := copyRDS3(db, rdsDB, removed, t, true); err != nil {
+			return errors.Wrapf(err, "cannot remove %s entries", t.table)
+		}
+		added := fmt.Sprintf("SELECT * FROM (%s EXCEPT %s) ORDER BY 1", t.selectFrom("main"), t.selectFrom("prev"))
+		if err := copyRDS3(db, rdsDB, added, t, false); err != nil {
+			return errors.Wrapf(err, "cannot add %s entries", t.table)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	badger "github.com/dgraph-io/badger/v4"
+	"github.com/je4/utils/v2/pkg/checksum"
+	"github.com/ocfl-archive/indexer/v3/pkg/indexer"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+const rds3Schema = `
+CREATE TABLE MFG (manufacturer_id INTEGER, name TEXT);
+CREATE TABLE OS (operating_system_id INTEGER, name TEXT, version TEXT, manufacturer_id INTEGER);
+CREATE TABLE PKG (package_id INTEGER, name TEXT, version TEXT, operating_system_id INTEGER, manufacturer_id INTEGER, language TEXT, application_type TEXT);
+CREATE TABLE FILE (sha256 TEXT, sha1 TEXT, md5 TEXT, crc32 TEXT, file_name TEXT, file_size INTEGER, package_id INTEGER);
+INSERT INTO MFG VALUES (1, 'Hello Inc.');
+INSERT INTO OS VALUES (7, 'Hello OS', '1.0', 1);
+INSERT INTO PKG VALUES (42, 'Hello Product', '2.0', 7, 1, 'English', 'Tool');
+`
+
+// rds3TestRelease creates a RDS v3 database with the files as sha1 -> file name
+func rds3TestRelease(t *testing.T, name string, files map[string]string) string {
+	filename := filepath.Join(t.TempDir(), name)
+	db, err := sql.Open("sqlite", "file:"+filename)
+	if err != nil {
+		t.Fatalf("cannot create %s: %v", filename, err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(rds3Schema); err != nil {
+		t.Fatalf("cannot create schema: %v", err)
+	}
+	for sha1sum, fileName := range files {
+		if _, err := db.Exec("INSERT INTO FILE VALUES ('', ?, '', '', ?, 10, 42)", strings.ToLower(sha1sum), fileName); err != nil {
+			t.Fatalf("cannot insert %s: %v", fileName, err)
+		}
+	}
+	return filename
+}
+
+// rds3Keys returns the file names of the sha1 checksums in the badger database
+func rds3Keys(t *testing.T, folder string, sha1sums ...string) map[string]string {
+	db, err := badger.Open(badger.DefaultOptions(folder).WithLogger(nil).WithReadOnly(true))
+	if err != nil {
+		t.Fatalf("cannot open badger: %v", err)
+	}
+	defer db.Close()
+	store := indexer.NewNSRLBadgerStore(db)
+	keys := map[string]string{}
+	for _, sha1sum := range sha1sums {
+		entries, err := store.Get(indexer.NSRL_File + strings.ToUpper(sha1sum))
+		assert.NoError(t, err)
+		if len(entries) > 0 {
+			keys[sha1sum] = entries[0]["FileName"]
+		}
+	}
+	return keys
+}
+
+func TestRDS3(t *testing.T) {
+	sha1sum := func(content string) string {
+		s, err := checksum.Checksum(strings.NewReader(content), checksum.DigestSHA1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	kept, removed, added := sha1sum("kept"), sha1sum("removed"), sha1sum("added")
+	release1 := rds3TestRelease(t, "release1.db", map[string]string{kept: "kept.txt", removed: "removed.txt"})
+	release2 := rds3TestRelease(t, "release2.db", map[string]string{kept: "kept.txt", added: "added.txt"})
+
+	t.Run("full", func(t *testing.T) {
+		folder := t.TempDir()
+		assert.NoError(t, rds3([]string{release1}, "", "", folder, "SHA-1"))
+		assert.Equal(t, map[string]string{kept: "kept.txt", removed: "removed.txt"}, rds3Keys(t, folder, kept, removed, added))
+
+		// the keys can be read by the nsrl action
+		db, err := badger.Open(badger.DefaultOptions(folder).WithLogger(nil).WithReadOnly(true))
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer db.Close()
+		logger := zerolog.Nop()
+		ad := indexer.NewActionDispatcher(nil)
+		indexer.NewActionNSRL(indexer.NameNSRL, indexer.NewNSRLBadgerStore(db), ad, &logger)
+		result, err := ad.Stream(strings.NewReader("kept"), []string{"kept.txt"}, []string{indexer.NameNSRL})
+		if !assert.NoError(t, err) {
+			return
+		}
+		nsrlResult, ok := result.Metadata[indexer.NameNSRL].(*indexer.ActionNSRLResult)
+		if assert.True(t, ok) && assert.True(t, nsrlResult.Known) {
+			entry := nsrlResult.Entries[0]
+			assert.Equal(t, "Hello Product", entry.Prod["ProductName"])
+			assert.Equal(t, "Hello OS", entry.OS["OpSystemName"])
+			assert.Equal(t, "Hello Inc.", entry.ProdMfg["MfgName"])
+		}
+	})
+
+	t.Run("incremental", func(t *testing.T) {
+		folder := t.TempDir()
+		assert.NoError(t, rds3([]string{release1}, "", "", folder, "SHA-1"))
+		assert.NoError(t, rds3([]string{release2}, release1, "", folder, "SHA-1"))
+		assert.Equal(t, map[string]string{kept: "kept.txt", added: "added.txt"}, rds3Keys(t, folder, kept, removed, added))
+	})
+
+	t.Run("delta", func(t *testing.T) {
+		folder := t.TempDir()
+		delta := filepath.Join(t.TempDir(), "delta.sql")
+		assert.NoError(t, os.WriteFile(delta, []byte(
+			"DELETE FROM FILE WHERE file_name = 'removed.txt';\n"+
+				"INSERT INTO FILE VALUES ('', '"+added+"', '', '', 'added.txt', 10, 42);\n"), 0644))
+		assert.NoError(t, rds3([]string{release1}, "", "", folder, "SHA-1"))
+		assert.NoError(t, rds3(nil, release1, delta, folder, "SHA-1"))
+		assert.Equal(t, map[string]string{kept: "kept.txt", added: "added.txt"}, rds3Keys(t, folder, kept, removed, added))
+	})
+}
+
+func TestRDS3Tables(t *testing.T) {
+	tables, err := rds3Tables("")
+	if !assert.NoError(t, err) {
+		return
+	}
+	file := tables[len(tables)-1]
+	assert.Equal(t, "FILE", file.table)
+	assert.Equal(t, indexer.NSRL_File, file.keyPrefix)
+	assert.Equal(t, "sha1", file.columns[0])
+	assert.Equal(t, "SELECT sha1, file_name, file_size, package_id FROM prev.FILE", file.selectFrom("prev"))
+
+	tables, err = rds3Tables("md5")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "MD5-", tables[len(tables)-1].keyPrefix)
+	}
+	_, err = rds3Tables("sha256")
+	assert.Error(t, err)
+}
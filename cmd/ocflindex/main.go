@@ -0,0 +1,177 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/je4/utils/v2/pkg/zLogger"
+	"github.com/ocfl-archive/indexer/v3/pkg/indexer"
+	"github.com/ocfl-archive/indexer/v3/pkg/util"
+	ublogger "gitlab.switch.ch/ub-unibas/go-ublogger/v2"
+	"go.ub.unibas.ch/cloud/certloader/v2/pkg/loader"
+)
+
+const INDEXER = "ocfl indexer v0.1, info-age GmbH Basel"
+
+var configFile = flag.String("cfg", "", "config file location")
+var extension = flag.String("extension", indexer.OCFLExtensionName, "name of the folder in the extensions directory of the object")
+var actionList = flag.String("actions", "", "comma separated list of actions (default all)")
+var dryRun = flag.Bool("dryrun", false, "write results to stdout instead of the object")
+
+func splitList(list string) []string {
+	var result []string
+	for _, s := range strings.Split(list, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func main() {
+	var err error
+	println(INDEXER)
+
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatalf("usage: %s [flags] <ocfl object root>...", os.Args[0])
+	}
+
+	var exPath = ""
+	// if configfile not found try path of executable as prefix
+	if !indexer.FileExists(*configFile) {
+		ex, err := os.Executable()
+		if err != nil {
+			panic(err)
+		}
+		exPath = filepath.Dir(ex)
+		if indexer.FileExists(filepath.Join(exPath, *configFile)) {
+			*configFile = filepath.Join(exPath, *configFile)
+		}
+	}
+	// configfile should exists at this place
+	conf := LoadConfig(*configFile)
+
+	var loggerTLSConfig *tls.Config
+	var loggerLoader io.Closer
+	if conf.Log.Stash.TLS != nil {
+		loggerTLSConfig, loggerLoader, err = loader.CreateClientLoader(conf.Log.Stash.TLS, nil)
+		if err != nil {
+			log.Fatalf("cannot create client loader: %v", err)
+		}
+		defer func(loggerLoader io.Closer) {
+			err := loggerLoader.Close()
+			if err != nil {
+				log.Printf("cannot close logger loader: %v", err)
+			}
+		}(loggerLoader)
+	}
+
+	// create logger instance
+	_logger, _logstash, _logfile, err := ublogger.CreateUbMultiLoggerTLS(conf.Log.Level, conf.Log.File,
+		ublogger.SetDataset(conf.Log.Stash.Dataset),
+		ublogger.SetLogStash(conf.Log.Stash.LogstashHost, conf.Log.Stash.LogstashPort, conf.Log.Stash.Namespace, conf.Log.Stash.LogstashTraceLevel),
+		ublogger.SetTLS(conf.Log.Stash.TLS != nil),
+		ublogger.SetTLSConfig(loggerTLSConfig),
+	)
+	if err != nil {
+		log.Fatalf("cannot create logger: %v", err)
+	}
+	if _logstash != nil {
+		defer _logstash.Close()
+	}
+	if _logfile != nil {
+		defer _logfile.Close()
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Fatalf("cannot get hostname: %v", err)
+	}
+	l2 := _logger.With().Timestamp().Str("host", hostname).Logger() //.Output(output)
+	var logger zLogger.ZLogger = &l2
+
+	ad, actions, closer, err := util.InitIndexer(conf.Indexer, logger)
+	if err != nil {
+		log.Fatalf("Error initializing indexer: %v", err)
+	}
+	defer func(closer io.Closer) {
+		err := closer.Close()
+		if err != nil {
+			logger.Error().Msgf("error closing indexer: %v", err)
+		}
+	}(closer)
+
+	if handler := ad.MetricsHandler(); handler != nil && conf.Indexer.Metrics.Addr != "" {
+		metricsServer, err := util.ServeMetrics(conf.Indexer.Metrics.Addr, handler, logger)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("cannot start metrics endpoint")
+		}
+		defer metricsServer.Close()
+	}
+
+	if requested := splitList(*actionList); len(requested) > 0 {
+		for _, name := range requested {
+			if !slices.Contains(actions, name) {
+				logger.Fatal().Msgf("action '%s' not available", name)
+			}
+		}
+		actions = requested
+	}
+
+	// abort on ctrl-c without writing the index of the current object
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	for _, objectRoot := range flag.Args() {
+		index, err := indexer.IndexOCFLObject(ctx, ad.ActionDispatcher(), os.DirFS(objectRoot), actions)
+		if ctx.Err() != nil {
+			logger.Error().Err(err).Msgf("indexing of %s aborted", objectRoot)
+			break
+		}
+		if err != nil {
+			logger.Error().Err(err).Msgf("cannot index ocfl object %s", objectRoot)
+			continue
+		}
+		for digest, fi := range index.Files {
+			if fi.Error != "" {
+				logger.Error().Msgf("%s - %s: %s", index.ID, digest, fi.Error)
+			}
+		}
+		if *dryRun {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(index); err != nil {
+				logger.Error().Err(err).Msg("cannot write result")
+			}
+			continue
+		}
+		filename, err := indexer.WriteOCFLSidecar(objectRoot, *extension, index)
+		if err != nil {
+			logger.Error().Err(err).Msgf("cannot write index of %s", index.ID)
+			continue
+		}
+		logger.Info().Msgf("%s: %d files indexed to %s", index.ID, len(index.Files), filename)
+	}
+}
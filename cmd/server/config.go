@@ -0,0 +1,72 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package main
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/je4/utils/v2/pkg/stashconfig"
+	"github.com/ocfl-archive/indexer/v3/pkg/indexer"
+)
+
+type Config struct {
+	ErrorTemplate string                 `toml:"errortemplate"`
+	AccessLog     string                 `toml:"accesslog"`
+	CertPEM       string                 `toml:"certpem"`
+	KeyPEM        string                 `toml:"keypem"`
+	Addr          string                 `toml:"addr"`
+	MaxUploadSize int64                  `toml:"maxuploadsize"`
+	GRPCAddr      string                 `toml:"grpcaddr"`
+	JwtKey        string                 `toml:"jwtkey"`
+	JwtAlg        []string               `toml:"jwtalg"`
+	SFTP          indexer.ConfigSFTP     `toml:"sftp"`
+	Indexer       *indexer.IndexerConfig `toml:"indexer"`
+	Log           stashconfig.Config     `toml:"log"`
+}
+
+func LoadConfig(fp string) *Config {
+	var conf = &Config{
+		Addr:          "localhost:8000",
+		MaxUploadSize: 4 << 30,
+		JwtAlg:        []string{"HS256", "HS384", "HS512"},
+		Indexer:       indexer.GetDefaultConfig(),
+	}
+
+	if fp == "" {
+		return conf
+	}
+
+	if _, err := toml.DecodeFile(fp, conf); err != nil {
+		log.Fatalln("Error on loading config: ", err)
+	}
+	if pwd := os.Getenv("SFTP_PASSWORD"); pwd != "" {
+		conf.SFTP.Password = pwd
+	}
+	if key := os.Getenv("JWT_KEY"); key != "" {
+		conf.JwtKey = key
+	}
+	// jwtkey is a shared secret, so only HMAC algorithms can validate
+	for i, alg := range conf.JwtAlg {
+		conf.JwtAlg[i] = strings.ToUpper(alg)
+		if _, ok := jwt.GetSigningMethod(conf.JwtAlg[i]).(*jwt.SigningMethodHMAC); !ok {
+			log.Fatalf("jwtalg '%s' not supported, jwtkey needs HS256, HS384 or HS512", alg)
+		}
+	}
+
+	return conf
+}
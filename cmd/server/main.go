@@ -0,0 +1,230 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"html/template"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/je4/utils/v2/pkg/zLogger"
+	"github.com/ocfl-archive/indexer/v3/pkg/indexer"
+	"github.com/ocfl-archive/indexer/v3/pkg/indexergrpc"
+	"github.com/ocfl-archive/indexer/v3/pkg/util"
+	ublogger "gitlab.switch.ch/ub-unibas/go-ublogger/v2"
+	"go.ub.unibas.ch/cloud/certloader/v2/pkg/loader"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+const INDEXER = "indexer server v0.2, info-age GmbH Basel"
+
+var configFile = flag.String("cfg", "", "config file location")
+
+func main() {
+	var err error
+	println(INDEXER)
+
+	flag.Parse()
+
+	// if configfile not found try path of executable as prefix
+	if !indexer.FileExists(*configFile) {
+		ex, err := os.Executable()
+		if err != nil {
+			panic(err)
+		}
+		exPath := filepath.Dir(ex)
+		if indexer.FileExists(filepath.Join(exPath, *configFile)) {
+			*configFile = filepath.Join(exPath, *configFile)
+		}
+	}
+	conf := LoadConfig(*configFile)
+
+	var loggerTLSConfig *tls.Config
+	var loggerLoader io.Closer
+	if conf.Log.Stash.TLS != nil {
+		loggerTLSConfig, loggerLoader, err = loader.CreateClientLoader(conf.Log.Stash.TLS, nil)
+		if err != nil {
+			log.Fatalf("cannot create client loader: %v", err)
+		}
+		defer func(loggerLoader io.Closer) {
+			err := loggerLoader.Close()
+			if err != nil {
+				log.Printf("cannot close logger loader: %v", err)
+			}
+		}(loggerLoader)
+	}
+
+	// create logger instance
+	_logger, _logstash, _logfile, err := ublogger.CreateUbMultiLoggerTLS(conf.Log.Level, conf.Log.File,
+		ublogger.SetDataset(conf.Log.Stash.Dataset),
+		ublogger.SetLogStash(conf.Log.Stash.LogstashHost, conf.Log.Stash.LogstashPort, conf.Log.Stash.Namespace, conf.Log.Stash.LogstashTraceLevel),
+		ublogger.SetTLS(conf.Log.Stash.TLS != nil),
+		ublogger.SetTLSConfig(loggerTLSConfig),
+	)
+	if err != nil {
+		log.Fatalf("cannot create logger: %v", err)
+	}
+	if _logstash != nil {
+		defer _logstash.Close()
+	}
+	if _logfile != nil {
+		defer _logfile.Close()
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Fatalf("cannot get hostname: %v", err)
+	}
+	l2 := _logger.With().Timestamp().Str("host", hostname).Logger()
+	var logger zLogger.ZLogger = &l2
+
+	ad, actions, closer, err := util.InitIndexer(conf.Indexer, logger)
+	if err != nil {
+		log.Fatalf("Error initializing indexer: %v", err)
+	}
+	defer func(closer io.Closer) {
+		err := closer.Close()
+		if err != nil {
+			logger.Error().Msgf("error closing indexer: %v", err)
+		}
+	}(closer)
+
+	var mapping = map[string]string{}
+	for _, val := range conf.Indexer.FileMap {
+		mapping[strings.ToLower(val.Alias)] = val.Folder
+	}
+	fm := indexer.NewFileMapper(mapping)
+
+	var sftp *indexer.SFTP
+	if conf.SFTP.Password != "" || len(conf.SFTP.PrivateKey) > 0 {
+		sftp, err = indexer.NewSFTP(conf.SFTP.PrivateKey, conf.SFTP.Password, conf.SFTP.Knownhosts, zLogger.NewZWrapper(logger))
+		if err != nil {
+			logger.Fatal().Err(err).Msg("cannot initialize sftp")
+		}
+	}
+
+	var urlRegexp []*regexp.Regexp
+	for _, expr := range conf.Indexer.URLRegexp {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("cannot compile url regexp '%s'", expr)
+		}
+		urlRegexp = append(urlRegexp, re)
+	}
+
+	var downloadMime *regexp.Regexp
+	if conf.Indexer.DownloadMime != "" {
+		if downloadMime, err = regexp.Compile(conf.Indexer.DownloadMime); err != nil {
+			logger.Fatal().Err(err).Msgf("cannot compile forcedownload regexp '%s'", conf.Indexer.DownloadMime)
+		}
+	}
+	ad.ActionDispatcher().SetSources(fm, sftp, urlRegexp, downloadMime)
+
+	var errorTemplate *template.Template
+	if conf.ErrorTemplate != "" {
+		errorTemplate, err = template.ParseFiles(conf.ErrorTemplate)
+		if err != nil {
+			logger.Error().Err(err).Msgf("cannot parse error template %s", conf.ErrorTemplate)
+		}
+	}
+
+	var accessLog io.Writer
+	if conf.AccessLog != "" {
+		fp, err := os.OpenFile(conf.AccessLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("cannot open access log %s", conf.AccessLog)
+		}
+		defer fp.Close()
+		accessLog = fp
+	}
+
+	if conf.JwtKey == "" {
+		logger.Warn().Msg("no jwtkey configured, authentication disabled")
+	}
+
+	srv := NewServer(ad.ActionDispatcher(), actions, conf.MaxUploadSize, conf.JwtKey, conf.JwtAlg, errorTemplate, accessLog, logger)
+	httpServer := &http.Server{
+		Addr:    conf.Addr,
+		Handler: srv.Handler(),
+	}
+
+	var grpcServer *grpc.Server
+	if conf.GRPCAddr != "" {
+		var opts []grpc.ServerOption
+		if conf.CertPEM != "" && conf.KeyPEM != "" {
+			creds, err := credentials.NewServerTLSFromFile(conf.CertPEM, conf.KeyPEM)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("cannot load grpc tls credentials")
+			}
+			opts = append(opts, grpc.Creds(creds))
+		}
+		unary, stream := indexergrpc.TraceInterceptors()
+		opts = append(opts, grpc.ChainUnaryInterceptor(unary), grpc.ChainStreamInterceptor(stream))
+		if conf.JwtKey != "" {
+			unary, stream := indexergrpc.JWTInterceptors(conf.JwtKey, conf.JwtAlg)
+			opts = append(opts, grpc.ChainUnaryInterceptor(unary), grpc.ChainStreamInterceptor(stream))
+		}
+		grpcServer = grpc.NewServer(opts...)
+		indexergrpc.NewServer(ad.ActionDispatcher(), conf.MaxUploadSize, logger).Register(grpcServer)
+		listener, err := net.Listen("tcp", conf.GRPCAddr)
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("cannot listen on %s", conf.GRPCAddr)
+		}
+		go func() {
+			logger.Info().Msgf("starting grpc server at %s", conf.GRPCAddr)
+			if err := grpcServer.Serve(listener); err != nil {
+				logger.Error().Err(err).Msg("grpc server error")
+			}
+		}()
+	}
+
+	go func() {
+		sigint := make(chan os.Signal, 1)
+		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
+		<-sigint
+		logger.Info().Msg("shutting down server")
+		if grpcServer != nil {
+			grpcServer.GracefulStop()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(ctx); err != nil {
+			logger.Error().Err(err).Msg("cannot shutdown server")
+		}
+	}()
+
+	logger.Info().Msgf("starting server at %s with actions %v", conf.Addr, actions)
+	if conf.CertPEM != "" && conf.KeyPEM != "" {
+		err = httpServer.ListenAndServeTLS(conf.CertPEM, conf.KeyPEM)
+	} else {
+		err = httpServer.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error().Err(err).Msg("server error")
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/je4/utils/v2/pkg/zLogger"
+	"github.com/ocfl-archive/indexer/v3/pkg/indexer"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Server offers the indexer via http
+//
+//	POST /v2/upload?filename=<name>&action=<action>  body is the data to index
+//	POST /v2/url  body is json {"url": "<file|sftp|http|https url>", "actions": ["<action>"]}
+//
+// Without action parameters, all configured stream actions are used. Uploads larger than maxUploadSize are rejected.
+type Server struct {
+	ad            *indexer.ActionDispatcher
+	actions       []string
+	maxUploadSize int64
+	jwtKey        string
+	jwtAlg        []string
+	errorTemplate *template.Template
+	accessLog     io.Writer
+	accessLogLock sync.Mutex
+	logger        zLogger.ZLogger
+}
+
+func NewServer(ad *indexer.ActionDispatcher, actions []string, maxUploadSize int64, jwtKey string, jwtAlg []string, errorTemplate *template.Template, accessLog io.Writer, logger zLogger.ZLogger) *Server {
+	return &Server{
+		ad:            ad,
+		actions:       actions,
+		maxUploadSize: maxUploadSize,
+		jwtKey:        jwtKey,
+		jwtAlg:        jwtAlg,
+		errorTemplate: errorTemplate,
+		accessLog:     accessLog,
+		logger:        logger,
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("POST /v2/upload", s.auth(http.HandlerFunc(s.upload)))
+	mux.Handle("POST /v2/url", s.auth(http.HandlerFunc(s.indexURL)))
+	mux.HandleFunc("GET /v2/actions", func(w http.ResponseWriter, r *http.Request) {
+		s.writeJSON(w, s.actions)
+	})
+	if metrics := s.ad.Metrics(); metrics != nil {
+		mux.Handle("GET /metrics", s.auth(metrics.Handler()))
+	}
+	return s.log(s.trace(mux))
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	sw.status = status
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	if sw.status == 0 {
+		sw.status = http.StatusOK
+	}
+	n, err := sw.ResponseWriter.Write(b)
+	sw.size += int64(n)
+	return n, err
+}
+
+// log writes an access log in common log format
+func (s *Server) log(next http.Handler) http.Handler {
+	if s.accessLog == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w}
+		start := time.Now()
+		next.ServeHTTP(sw, r)
+		s.accessLogLock.Lock()
+		defer s.accessLogLock.Unlock()
+		fmt.Fprintf(s.accessLog, "%s - - [%s] \"%s %s %s\" %d %d\n",
+			r.RemoteAddr, start.Format("02/Jan/2006:15:04:05 -0700"), r.Method, r.URL.RequestURI(), r.Proto, sw.status, sw.size)
+	})
+}
+
+// trace continues the trace context of the caller in the request context
+func (s *Server) trace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := indexer.TracePropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// auth checks the jwt from the Authorization header or the token parameter
+func (s *Server) auth(next http.Handler) http.Handler {
+	if s.jwtKey == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenStr := r.URL.Query().Get("token")
+		if bearer := r.Header.Get("Authorization"); bearer != "" {
+			var found bool
+			if tokenStr, found = strings.CutPrefix(bearer, "Bearer "); !found {
+				s.writeError(w, r, http.StatusUnauthorized, "no Bearer in Authorization header")
+				return
+			}
+		}
+		if tokenStr == "" {
+			s.writeError(w, r, http.StatusUnauthorized, "no token")
+			return
+		}
+		if _, err := jwt.Parse(tokenStr, func(token *jwt.Token) (any, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, errors.Errorf("signing method %s not supported", token.Method.Alg())
+			}
+			return []byte(s.jwtKey), nil
+		}, jwt.WithValidMethods(s.jwtAlg)); err != nil {
+			s.writeError(w, r, http.StatusForbidden, fmt.Sprintf("invalid token: %v", err))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		s.logger.Error().Err(err).Msg("cannot write json response")
+	}
+}
+
+// writeError sends html with the error template, if requested by the client, json otherwise
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	s.logger.Error().Msgf("%s %s: %d - %s", r.Method, r.URL.Path, status, message)
+	data := struct {
+		Status     int    `json:"status"`
+		StatusText string `json:"statustext"`
+		Message    string `json:"message"`
+	}{
+		Status:     status,
+		StatusText: http.StatusText(status),
+		Message:    message,
+	}
+	if s.errorTemplate != nil && strings.Contains(r.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		if err := s.errorTemplate.Execute(w, data); err != nil {
+			s.logger.Error().Err(err).Msg("cannot execute error template")
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// getActions returns the requested actions, all stream actions if none are requested
+func (s *Server) getActions(requested []string) ([]string, error) {
+	var actions []string
+	for _, a := range requested {
+		for _, name := range strings.Split(a, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			if !slices.Contains(s.actions, name) {
+				return nil, errors.Errorf("action '%s' not available", name)
+			}
+			actions = append(actions, name)
+		}
+	}
+	if len(actions) == 0 {
+		// actions without ACTSTREAM need a local file and must be requested explicitly
+		streamActions := s.ad.GetActionNamesByCaps(indexer.ACTSTREAM)
+		for _, name := range s.actions {
+			if slices.Contains(streamActions, name) {
+				actions = append(actions, name)
+			}
+		}
+	}
+	return actions, nil
+}
+
+func (s *Server) upload(w http.ResponseWriter, r *http.Request) {
+	actions, err := s.getActions(r.URL.Query()["action"])
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	filename := r.URL.Query().Get("filename")
+	body := r.Body
+	if s.maxUploadSize > 0 {
+		body = http.MaxBytesReader(w, r.Body, s.maxUploadSize)
+	}
+	result, err := s.ad.StreamContext(r.Context(), body, []string{filename}, actions)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		s.writeError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("upload larger than %d bytes", maxBytesErr.Limit))
+		return
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, fmt.Sprintf("cannot index upload: %v", err))
+		return
+	}
+	s.writeJSON(w, result)
+}
+
+type urlRequest struct {
+	URL     string   `json:"url"`
+	Actions []string `json:"actions"`
+}
+
+func (s *Server) indexURL(w http.ResponseWriter, r *http.Request) {
+	var req urlRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("cannot decode request: %v", err))
+		return
+	}
+	actions, err := s.getActions(req.Actions)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	uri, err := url.Parse(req.URL)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("cannot parse url '%s': %v", req.URL, err))
+		return
+	}
+	result, err := s.ad.IndexURL(r.Context(), uri, actions)
+	if errors.Is(err, indexer.ErrURLNotAllowed) {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("cannot index '%s': %v", req.URL, err))
+		return
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, fmt.Sprintf("cannot index '%s': %v", req.URL, err))
+		return
+	}
+	s.writeJSON(w, result)
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/je4/utils/v2/pkg/checksum"
+	"github.com/ocfl-archive/indexer/v3/pkg/indexer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const testJWTKey = "swordfish"
+
+// testServer creates a server with the stream action checksum and the file only action "exif"
+func testServer(t *testing.T, jwtKey string) *httptest.Server {
+	logger := zerolog.Nop()
+	ad := indexer.NewActionDispatcher(nil)
+	ad.SetTempDir(t.TempDir())
+	indexer.NewActionChecksum(indexer.NameChecksum, []checksum.DigestAlgorithm{checksum.DigestSHA1}, ad)
+	if _, err := indexer.NewActionExternal("exif", "http://localhost:1/exif/[[PATH]]", indexer.ACTFILE, indexer.EACTURL, "", nil, 0, ad); err != nil {
+		t.Fatal(err)
+	}
+	folder := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(folder, "test.txt"), []byte(strings.Repeat("plain text ", 100)), 0644))
+	ad.SetSources(indexer.NewFileMapper(map[string]string{"data": folder}), nil, nil, nil)
+
+	errorTemplate := template.Must(template.New("error").Parse("<html><body>{{.Status}}: {{.Message}}</body></html>"))
+	srv := NewServer(ad, []string{indexer.NameChecksum, "exif"}, 100, jwtKey, []string{"HS256"}, errorTemplate, nil, &logger)
+	server := httptest.NewServer(srv.Handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestServerAuth(t *testing.T) {
+	token, err := jwt.New(jwt.SigningMethodHS256).SignedString([]byte(testJWTKey))
+	assert.NoError(t, err)
+	wrongToken, err := jwt.New(jwt.SigningMethodHS256).SignedString([]byte("wrong"))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		jwtKey     string
+		header     string
+		query      string
+		wantStatus int
+	}{
+		{name: "disabled", wantStatus: http.StatusOK},
+		{name: "no token", jwtKey: testJWTKey, wantStatus: http.StatusUnauthorized},
+		{name: "no bearer", jwtKey: testJWTKey, header: token, wantStatus: http.StatusUnauthorized},
+		{name: "invalid token", jwtKey: testJWTKey, header: "Bearer " + wrongToken, wantStatus: http.StatusForbidden},
+		{name: "header", jwtKey: testJWTKey, header: "Bearer " + token, wantStatus: http.StatusOK},
+		{name: "parameter", jwtKey: testJWTKey, query: "&token=" + token, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := testServer(t, tt.jwtKey)
+			req, err := http.NewRequest(http.MethodPost, server.URL+"/v2/upload?filename=test.txt"+tt.query, strings.NewReader("plain text"))
+			assert.NoError(t, err)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if !assert.NoError(t, err) {
+				return
+			}
+			resp.Body.Close()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestServerUpload(t *testing.T) {
+	server := testServer(t, "")
+	tests := []struct {
+		name       string
+		query      string
+		data       string
+		wantStatus int
+		wantSHA1   bool
+	}{
+		{name: "default actions", query: "", data: "plain text", wantStatus: http.StatusOK, wantSHA1: true},
+		{name: "action", query: "&action=" + indexer.NameChecksum, data: "plain text", wantStatus: http.StatusOK, wantSHA1: true},
+		{name: "unknown action", query: "&action=unknown", data: "plain text", wantStatus: http.StatusBadRequest},
+		{name: "file action", query: "&action=exif", data: "plain text", wantStatus: http.StatusInternalServerError},
+		{name: "too large", data: strings.Repeat("x", 101), wantStatus: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(server.URL+"/v2/upload?filename=test.txt"+tt.query, "application/octet-stream", strings.NewReader(tt.data))
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			if !tt.wantSHA1 {
+				return
+			}
+			var result indexer.ResultV2
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+			assert.NotEmpty(t, result.Checksum[string(checksum.DigestSHA1)])
+		})
+	}
+}
+
+func TestServerURL(t *testing.T) {
+	server := testServer(t, "")
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "file", body: `{"url": "file://data/test.txt"}`, wantStatus: http.StatusOK},
+		{name: "invalid json", body: `{"url": `, wantStatus: http.StatusBadRequest},
+		{name: "invalid url", body: `{"url": "http://%zz"}`, wantStatus: http.StatusBadRequest},
+		{name: "scheme", body: `{"url": "ftp://localhost/test.txt"}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown action", body: `{"url": "file://data/test.txt", "actions": ["unknown"]}`, wantStatus: http.StatusBadRequest},
+		{name: "missing file", body: `{"url": "file://data/missing.txt"}`, wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(server.URL+"/v2/url", "application/json", strings.NewReader(tt.body))
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		})
+	}
+}
+
+func TestServerErrorTemplate(t *testing.T) {
+	server := testServer(t, "")
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/v2/upload?action=unknown", strings.NewReader("plain text"))
+	assert.NoError(t, err)
+	req.Header.Set("Accept", "text/html")
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	var body strings.Builder
+	_, err = io.Copy(&body, resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "<html><body>400: action &#39;unknown&#39; not available</body></html>", body.String())
+}
+
+func TestServerMetrics(t *testing.T) {
+	logger := zerolog.Nop()
+	ad := indexer.NewActionDispatcher(nil)
+	metrics, err := indexer.NewMetrics(prometheus.NewRegistry())
+	if !assert.NoError(t, err) {
+		return
+	}
+	ad.SetMetrics(metrics)
+	server := httptest.NewServer(NewServer(ad, nil, 100, testJWTKey, []string{"HS256"}, nil, nil, &logger).Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if !assert.NoError(t, err) {
+		return
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	token, err := jwt.New(jwt.SigningMethodHS256).SignedString([]byte(testJWTKey))
+	assert.NoError(t, err)
+	resp, err = http.Get(server.URL + "/metrics?token=" + token)
+	if !assert.NoError(t, err) {
+		return
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestServerTrace(t *testing.T) {
+	logger := zerolog.Nop()
+	exporter := tracetest.NewInMemoryExporter()
+	ad := indexer.NewActionDispatcher(nil)
+	ad.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	indexer.NewActionChecksum(indexer.NameChecksum, []checksum.DigestAlgorithm{checksum.DigestSHA1}, ad)
+	server := httptest.NewServer(NewServer(ad, nil, 100, "", nil, nil, nil, &logger).Handler())
+	defer server.Close()
+
+	const traceID, spanID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/v2/upload?filename=test.txt", strings.NewReader("plain text"))
+	assert.NoError(t, err)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+spanID+"-01")
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		return
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	spans := exporter.GetSpans()
+	if assert.NotEmpty(t, spans) {
+		root := spans[len(spans)-1]
+		assert.Equal(t, "Stream", root.Name)
+		assert.Equal(t, traceID, root.SpanContext.TraceID().String())
+		assert.Equal(t, spanID, root.Parent.SpanID().String())
+	}
+}
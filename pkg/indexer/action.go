@@ -14,9 +14,11 @@
 package indexer
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"regexp"
+	"time"
 
 	"emperror.dev/errors"
 )
@@ -29,6 +31,7 @@ const (
 	ACTHTTPS                               // capable of HTTPS
 	ACTHEAD                                // can deal with file head
 	ACTSTREAM                              // can deal with stream
+	ACTIDENT                               // identifies the format, runs before the other actions
 
 	ACTWEB      = ACTHTTPS | ACTHTTP
 	ACTALLPROTO = ACTFILE | ACTHTTP | ACTHTTPS
@@ -43,6 +46,7 @@ var ACTString map[ActionCapability]string = map[ActionCapability]string{
 	ACTHTTPS:  "ACTHTTPS",
 	ACTHEAD:   "ACTHEAD",
 	ACTSTREAM: "ACTSTREAM",
+	ACTIDENT:  "ACTIDENT",
 }
 
 var ACTAction map[string]ActionCapability = map[string]ActionCapability{
@@ -51,6 +55,7 @@ var ACTAction map[string]ActionCapability = map[string]ActionCapability{
 	"ACTHTTPS":  ACTHTTPS,
 	"ACTHEAD":   ACTHEAD,
 	"ACTSTREAM": ACTSTREAM,
+	"ACTIDENT":  ACTIDENT,
 }
 
 // for toml decoding
@@ -75,6 +80,31 @@ type Action interface {
 	GetWeight() uint
 }
 
+// ResultConsumer is implemented by actions, which can work on the merged result
+// of the other actions instead of reading the data themselves
+type ResultConsumer interface {
+	// CanUseResult returns true, if the results of the given actions contain everything needed
+	CanUseResult(actions []Action) bool
+	UseResult(result *ResultV2) (*ResultV2, error)
+}
+
+// PronomAction is implemented by actions, which choose their handling by the identified pronoms.
+// The dispatcher calls them, if CanHandle or CanHandlePronoms returns true.
+type PronomAction interface {
+	CanHandlePronoms(pronoms []string) bool
+}
+
+// ContextAction is implemented by actions, which call external tools or services.
+// If ctx is done, the child processes are killed and the requests aborted.
+// The timeout of the action applies within the deadline of ctx.
+type ContextAction interface {
+	StreamContext(ctx context.Context, contentType string, reader io.Reader, filename string) (*ResultV2, error)
+	DoV2Context(ctx context.Context, filename string) (*ResultV2, error)
+}
+
+// commandWaitDelay is the time to wait for the output of a killed child process
+const commandWaitDelay = 2 * time.Second
+
 type MimeWeightString struct {
 	Regexp string
 	Weight int
@@ -3,6 +3,7 @@ package indexer
 import (
 	"io"
 	"os"
+	"slices"
 
 	"emperror.dev/errors"
 	"github.com/je4/utils/v2/pkg/checksum"
@@ -37,6 +38,11 @@ func NewActionChecksum(name string, digests []checksum.DigestAlgorithm, ad *Acti
 	return as
 }
 
+// HasDigest returns true, if the digest algorithm is calculated by this action
+func (as *ActionChecksum) HasDigest(digest checksum.DigestAlgorithm) bool {
+	return slices.Contains(as.digests, digest)
+}
+
 func (as *ActionChecksum) GetWeight() uint {
 	return 10
 }
@@ -90,6 +96,9 @@ func (as *ActionChecksum) DoV2(filename string) (*ResultV2, error) {
 	}
 	var result = NewResultV2()
 	result.Metadata[as.GetName()] = checksums
+	for digest, val := range checksums {
+		result.Checksum[string(digest)] = val
+	}
 	return result, nil
 }
 
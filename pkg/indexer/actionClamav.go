@@ -14,15 +14,45 @@
 package indexer
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
 	"time"
 
 	"emperror.dev/errors"
 )
 
-func NewActionClamAV(name string, clamav string, wsl bool, timeout time.Duration, ad *ActionDispatcher) Action {
-	var caps = ACTFILEFULL
-	ac := &ActionClamAV{name: name, clamav: clamav, wsl: wsl, timeout: timeout, caps: caps}
+const (
+	ClamAVClean    = "clean"
+	ClamAVInfected = "infected"
+	ClamAVError    = "error"
+)
+
+var regexpClamAVFound = regexp.MustCompile(`^(.*): (.+) FOUND$`)
+var regexpClamAVOK = regexp.MustCompile(`^(.*): OK$`)
+var regexpClamAVError = regexp.MustCompile(`^(.*): (.+) ERROR$`)
+var regexpClamAVErrorReply = regexp.MustCompile(`^(.+) ERROR$`)
+
+// ClamAVResult is the structured result of a virus scan
+type ClamAVResult struct {
+	Status     string   `json:"status"`
+	Signatures []string `json:"signatures,omitempty"`
+	Message    string   `json:"message,omitempty"`
+}
+
+// NewActionClamAV creates a virus scanning action.
+// If clamd is not nil, data is sent to clamd via socket, otherwise the clamav binary is called.
+func NewActionClamAV(name string, clamav string, clamd *ClamdClient, wsl bool, timeout time.Duration, tempDir string, ad *ActionDispatcher) Action {
+	var caps = ACTFILEFULL | ACTSTREAM
+	if timeout == 0 {
+		timeout = time.Second * 60
+	}
+	ac := &ActionClamAV{name: name, clamav: clamav, clamd: clamd, wsl: wsl, timeout: timeout, tempDir: tempDir, caps: caps}
 	ad.RegisterAction(ac)
 	return ac
 }
@@ -30,14 +60,121 @@ func NewActionClamAV(name string, clamav string, wsl bool, timeout time.Duration
 type ActionClamAV struct {
 	name    string
 	clamav  string
+	clamd   *ClamdClient
 	wsl     bool
 	timeout time.Duration
+	tempDir string
 	caps    ActionCapability
 }
 
-func (ac *ActionClamAV) DoV2(filename string) (*ResultV2, error) {
-	//TODO implement me
-	panic("implement me")
+// parseClamAVOutput evaluates the output of clamscan/clamdscan with --no-summary
+func parseClamAVOutput(output string) *ClamAVResult {
+	var result = &ClamAVResult{Status: ClamAVClean, Signatures: []string{}}
+	var messages = []string{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if matches := regexpClamAVFound.FindStringSubmatch(line); matches != nil {
+			result.Status = ClamAVInfected
+			result.Signatures = append(result.Signatures, matches[2])
+			continue
+		}
+		if regexpClamAVOK.MatchString(line) {
+			continue
+		}
+		if matches := regexpClamAVError.FindStringSubmatch(line); matches != nil {
+			if result.Status != ClamAVInfected {
+				result.Status = ClamAVError
+			}
+			messages = append(messages, matches[2])
+			continue
+		}
+		if matches := regexpClamAVErrorReply.FindStringSubmatch(line); matches != nil {
+			if result.Status != ClamAVInfected {
+				result.Status = ClamAVError
+			}
+			messages = append(messages, matches[1])
+			continue
+		}
+		if strings.HasPrefix(line, "ERROR:") || strings.HasPrefix(line, "WARNING:") {
+			if strings.HasPrefix(line, "ERROR:") && result.Status != ClamAVInfected {
+				result.Status = ClamAVError
+			}
+			messages = append(messages, strings.TrimSpace(line))
+		}
+	}
+	result.Message = strings.Join(messages, "; ")
+	return result
+}
+
+// scanResult returns the result of a scan, a scan error is stored with errID
+func (ac *ActionClamAV) scanResult(clamResult *ClamAVResult, errID errorID) *ResultV2 {
+	var result = NewResultV2()
+	result.Metadata[ac.GetName()] = clamResult
+	if clamResult.Status == ClamAVError {
+		result.Errors[ac.GetName()] = clamResult.Message
+		result.ActionErrors = append(result.ActionErrors, NewActionError(errID, ac.GetName(), clamResult.Message))
+	}
+	return result
+}
+
+func (ac *ActionClamAV) DoV2Context(ctx context.Context, filename string) (*ResultV2, error) {
+	if ac.clamd != nil {
+		fp, err := os.Open(filename)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot open file '%s'", filename)
+		}
+		defer fp.Close()
+		clamResult, err := ac.clamd.InStreamContext(ctx, fp)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot scan file '%s'", filename)
+		}
+		return ac.scanResult(clamResult, IndexerServiceUnavailable), nil
+	}
+	cmdparam := []string{"--no-summary", "--stdout"}
+	cmdfile := ac.clamav
+	if ac.wsl {
+		cmdparam = append([]string{cmdfile}, append(cmdparam, pathToWSL(filename))...)
+		cmdfile = "wsl"
+	} else {
+		cmdparam = append(cmdparam, filename)
+	}
+
+	var out bytes.Buffer
+	ctx, cancel := context.WithTimeout(ctx, ac.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, cmdfile, cmdparam...)
+	cmd.WaitDelay = commandWaitDelay
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	// exit code 1 means "virus found", everything above is an error
+	var exitCode int
+	if err := runCommand(ctx, cmd); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || ctx.Err() != nil {
+			return nil, errors.Wrapf(err, "error executing (%s %s) for file '%s': %v", cmdfile, cmdparam, filename, out.String())
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	clamResult := parseClamAVOutput(out.String())
+	switch exitCode {
+	case 0:
+	case 1:
+		clamResult.Status = ClamAVInfected
+	default:
+		clamResult.Status = ClamAVError
+		if clamResult.Message == "" {
+			clamResult.Message = strings.TrimSpace(out.String())
+		}
+	}
+
+	return ac.scanResult(clamResult, IndexerExitCode), nil
 }
 
 func (ac *ActionClamAV) CanHandle(contentType string, filename string) bool {
@@ -45,7 +182,36 @@ func (ac *ActionClamAV) CanHandle(contentType string, filename string) bool {
 }
 
 func (ac *ActionClamAV) Stream(contentType string, reader io.Reader, filename string) (*ResultV2, error) {
-	return nil, errors.New("clamav does not support streaming")
+	return ac.StreamContext(context.Background(), contentType, reader, filename)
+}
+
+func (ac *ActionClamAV) DoV2(filename string) (*ResultV2, error) {
+	return ac.DoV2Context(context.Background(), filename)
+}
+
+// StreamContext sends the data to clamd or spools it to a temporary file, because clamscan needs a path
+func (ac *ActionClamAV) StreamContext(ctx context.Context, contentType string, reader io.Reader, filename string) (*ResultV2, error) {
+	if ac.clamd != nil {
+		clamResult, err := ac.clamd.InStreamContext(ctx, reader)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot scan '%s'", filename)
+		}
+		return ac.scanResult(clamResult, IndexerServiceUnavailable), nil
+	}
+	tmpFile, err := os.CreateTemp(ac.tempDir, "clamav-*")
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create temporary file")
+	}
+	tmpName := tmpFile.Name()
+	defer os.Remove(tmpName)
+	if _, err := io.Copy(tmpFile, reader); err != nil {
+		tmpFile.Close()
+		return nil, errors.Wrapf(err, "cannot write data of '%s' to '%s'", filename, tmpName)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return nil, errors.Wrapf(err, "cannot close temporary file '%s'", tmpName)
+	}
+	return ac.DoV2Context(ctx, tmpName)
 }
 
 func (ac *ActionClamAV) GetWeight() uint {
@@ -61,5 +227,6 @@ func (ac *ActionClamAV) GetName() string {
 }
 
 var (
-	_ Action = &ActionClamAV{}
+	_ Action        = &ActionClamAV{}
+	_ ContextAction = &ActionClamAV{}
 )
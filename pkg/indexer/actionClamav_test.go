@@ -0,0 +1,209 @@
+package indexer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseClamAVOutput(t *testing.T) {
+	tests := []struct {
+		name           string
+		output         string
+		wantStatus     string
+		wantSignatures []string
+		wantMessage    string
+	}{
+		{
+			name:           "clean",
+			output:         "/tmp/clamav-123: OK\n",
+			wantStatus:     ClamAVClean,
+			wantSignatures: []string{},
+		},
+		{
+			name:           "infected",
+			output:         "/tmp/clamav-123: Win.Test.EICAR_HDB-1 FOUND\n",
+			wantStatus:     ClamAVInfected,
+			wantSignatures: []string{"Win.Test.EICAR_HDB-1"},
+		},
+		{
+			name:           "file error",
+			output:         "/tmp/clamav-123: Access denied. ERROR\n",
+			wantStatus:     ClamAVError,
+			wantSignatures: []string{},
+			wantMessage:    "Access denied.",
+		},
+		{
+			name:           "daemon error",
+			output:         "ERROR: Could not connect to clamd on LocalSocket /run/clamav/clamd.ctl: No such file or directory\n",
+			wantStatus:     ClamAVError,
+			wantSignatures: []string{},
+			wantMessage:    "ERROR: Could not connect to clamd on LocalSocket /run/clamav/clamd.ctl: No such file or directory",
+		},
+		{
+			name:           "clamd size limit",
+			output:         "INSTREAM size limit exceeded. ERROR",
+			wantStatus:     ClamAVError,
+			wantSignatures: []string{},
+			wantMessage:    "INSTREAM size limit exceeded.",
+		},
+		{
+			name:           "empty",
+			output:         "",
+			wantStatus:     ClamAVClean,
+			wantSignatures: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseClamAVOutput(tt.output)
+			assert.Equal(t, tt.wantStatus, result.Status)
+			assert.Equal(t, tt.wantSignatures, result.Signatures)
+			assert.Equal(t, tt.wantMessage, result.Message)
+		})
+	}
+}
+
+// fakeClamd emulates the INSTREAM and PING commands of clamd
+func fakeClamd(t *testing.T, streamMaxLength int) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("cannot listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				br := bufio.NewReader(conn)
+				cmd, err := br.ReadString('\x00')
+				if err != nil {
+					return
+				}
+				switch cmd {
+				case "zPING\x00":
+					conn.Write([]byte("PONG\x00"))
+				case "zINSTREAM\x00":
+					var data []byte
+					for {
+						var size uint32
+						if err := binary.Read(br, binary.BigEndian, &size); err != nil {
+							return
+						}
+						if size == 0 {
+							break
+						}
+						if len(data)+int(size) > streamMaxLength {
+							conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+							// drain to avoid connection resets before the client reads the reply
+							io.Copy(io.Discard, br)
+							return
+						}
+						chunk := make([]byte, size)
+						if _, err := io.ReadFull(br, chunk); err != nil {
+							return
+						}
+						data = append(data, chunk...)
+					}
+					if bytes.Contains(data, []byte("EICAR")) {
+						conn.Write([]byte("stream: Win.Test.EICAR_HDB-1 FOUND\x00"))
+					} else {
+						conn.Write([]byte("stream: OK\x00"))
+					}
+				default:
+					conn.Write([]byte("UNKNOWN COMMAND\x00"))
+				}
+			}(conn)
+		}
+	}()
+	return "tcp://" + listener.Addr().String()
+}
+
+func TestClamdClient_InStream(t *testing.T) {
+	address := fakeClamd(t, 1024*1024)
+
+	tests := []struct {
+		name            string
+		data            []byte
+		streamMaxLength int64
+		wantStatus      string
+		wantSignatures  []string
+	}{
+		{
+			name:           "clean",
+			data:           []byte("hello world"),
+			wantStatus:     ClamAVClean,
+			wantSignatures: []string{},
+		},
+		{
+			name:           "infected",
+			data:           append(bytes.Repeat([]byte("x"), 200*1024), []byte("EICAR")...),
+			wantStatus:     ClamAVInfected,
+			wantSignatures: []string{"Win.Test.EICAR_HDB-1"},
+		},
+		{
+			name:            "client side limit",
+			data:            bytes.Repeat([]byte("x"), 200*1024),
+			streamMaxLength: 100 * 1024,
+			wantStatus:      ClamAVError,
+			wantSignatures:  []string{},
+		},
+		{
+			name:            "server side limit",
+			data:            bytes.Repeat([]byte("x"), 2*1024*1024),
+			streamMaxLength: 10 * 1024 * 1024,
+			wantStatus:      ClamAVError,
+			wantSignatures:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClamdClient(address, 5*time.Second, tt.streamMaxLength)
+			assert.NoError(t, err)
+			result, err := client.InStream(bytes.NewReader(tt.data))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, result.Status)
+			assert.Equal(t, tt.wantSignatures, result.Signatures)
+		})
+	}
+}
+
+func TestActionClamAV_StreamClamd(t *testing.T) {
+	client, err := NewClamdClient(fakeClamd(t, 1024*1024), 5*time.Second, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, client.Ping())
+
+	ad := NewActionDispatcher(nil)
+	NewActionClamAV(NameClamav, "", client, false, 0, "", ad)
+	result, err := ad.Stream(strings.NewReader("X5O!P%@AP[4\\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*"), []string{"eicar.com"}, []string{NameClamav})
+	assert.NoError(t, err)
+	assert.Equal(t, &ClamAVResult{Status: ClamAVInfected, Signatures: []string{"Win.Test.EICAR_HDB-1"}}, result.Metadata[NameClamav])
+}
+
+func TestActionClamAV_StreamClamdError(t *testing.T) {
+	client, err := NewClamdClient(fakeClamd(t, 1024), 5*time.Second, 0)
+	assert.NoError(t, err)
+
+	ad := NewActionDispatcher(nil)
+	NewActionClamAV(NameClamav, "", client, false, 0, "", ad)
+	result, err := ad.Stream(strings.NewReader(strings.Repeat("x", 4096)), []string{"test.bin"}, []string{NameClamav})
+	if !assert.NoError(t, err) || !assert.Len(t, result.ActionErrors, 1) {
+		return
+	}
+	assert.Equal(t, "INSTREAM size limit exceeded.", result.Errors[NameClamav])
+	assert.Equal(t, errorID(IndexerServiceUnavailable), result.ActionErrors[0].ID)
+	assert.Equal(t, NameClamav, result.ActionErrors[0].Action)
+}
@@ -0,0 +1,286 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package indexer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+)
+
+// input modes of command actions
+const (
+	CommandInputStdin = "stdin"
+	CommandInputFile  = "file"
+)
+
+// output formats of command actions
+const (
+	CommandOutputJSON     = "json"
+	CommandOutputKeyValue = "keyvalue"
+	CommandOutputRegexp   = "regexp"
+)
+
+// NewActionCommand creates an action calling a program, configured without code.
+// In args, [[PATH]] is replaced by the path of the file and [[FILENAME]] by its base name.
+// With input "stdin" the data is sent to stdin, with input "file" the stream is spooled to a temporary file.
+// The output is parsed as json, as key/value lines with separator or with the named groups of outputRegexp.
+// mapping works like in NewActionExternal.
+func NewActionCommand(name, command string, args []string, wsl bool, input, output, separator, outputRegexp string, mapping map[string]string, mimetype string, weight uint, timeout time.Duration, tempDir string, ad *ActionDispatcher) (Action, error) {
+	if err := checkMapping(mapping); err != nil {
+		return nil, errors.Wrapf(err, "invalid mapping for command action %s", name)
+	}
+	if input == "" {
+		input = CommandInputFile
+	}
+	if input != CommandInputStdin && input != CommandInputFile {
+		return nil, errors.Errorf("invalid input '%s' for command action %s", input, name)
+	}
+	var outRegexp *regexp.Regexp
+	switch output {
+	case "":
+		output = CommandOutputJSON
+	case CommandOutputJSON:
+	case CommandOutputKeyValue:
+		if separator == "" {
+			separator = "="
+		}
+	case CommandOutputRegexp:
+		var err error
+		if outRegexp, err = regexp.Compile(outputRegexp); err != nil {
+			return nil, errors.Wrapf(err, "invalid output regexp '%s' for command action %s", outputRegexp, name)
+		}
+	default:
+		return nil, errors.Errorf("invalid output '%s' for command action %s", output, name)
+	}
+	var mimeRegexp *regexp.Regexp
+	if mimetype != "" {
+		var err error
+		if mimeRegexp, err = regexp.Compile(mimetype); err != nil {
+			return nil, errors.Wrapf(err, "invalid mimetype regexp '%s' for command action %s", mimetype, name)
+		}
+	}
+	if weight == 0 {
+		weight = 100
+	}
+	if timeout == 0 {
+		timeout = time.Second * 60
+	}
+	ac := &ActionCommand{
+		name:      name,
+		command:   command,
+		args:      args,
+		wsl:       wsl,
+		input:     input,
+		output:    output,
+		separator: separator,
+		regexp:    outRegexp,
+		mapping:   mapping,
+		mimetype:  mimeRegexp,
+		weight:    weight,
+		timeout:   timeout,
+		tempDir:   tempDir,
+		caps:      ACTFILEFULL | ACTSTREAM,
+	}
+	ad.RegisterAction(ac)
+	return ac, nil
+}
+
+type ActionCommand struct {
+	name      string
+	command   string
+	args      []string
+	wsl       bool
+	input     string
+	output    string
+	separator string
+	regexp    *regexp.Regexp
+	mapping   map[string]string
+	mimetype  *regexp.Regexp
+	weight    uint
+	timeout   time.Duration
+	tempDir   string
+	caps      ActionCapability
+}
+
+func (ac *ActionCommand) Stream(contentType string, reader io.Reader, filename string) (*ResultV2, error) {
+	return ac.StreamContext(context.Background(), contentType, reader, filename)
+}
+
+func (ac *ActionCommand) DoV2(filename string) (*ResultV2, error) {
+	return ac.DoV2Context(context.Background(), filename)
+}
+
+func (ac *ActionCommand) DoV2Context(ctx context.Context, filename string) (*ResultV2, error) {
+	if ac.input == CommandInputStdin {
+		reader, err := os.Open(filename)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot open file '%s'", filename)
+		}
+		defer reader.Close()
+		return ac.run(ctx, reader, filename)
+	}
+	return ac.run(ctx, nil, filename)
+}
+
+func (ac *ActionCommand) CanHandle(contentType string, filename string) bool {
+	if ac.mimetype != nil && contentType != "" {
+		return ac.mimetype.MatchString(contentType)
+	}
+	return true
+}
+
+func (ac *ActionCommand) StreamContext(ctx context.Context, contentType string, reader io.Reader, filename string) (*ResultV2, error) {
+	if ac.input == CommandInputStdin {
+		return ac.run(ctx, reader, filename)
+	}
+	tmpFile, err := os.CreateTemp(ac.tempDir, "command-*"+filepath.Ext(filename))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create temporary file")
+	}
+	tmpName := tmpFile.Name()
+	defer os.Remove(tmpName)
+	if _, err := io.Copy(tmpFile, reader); err != nil {
+		tmpFile.Close()
+		return nil, errors.Wrapf(err, "cannot write data of '%s' to '%s'", filename, tmpName)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return nil, errors.Wrapf(err, "cannot close temporary file '%s'", tmpName)
+	}
+	return ac.run(ctx, nil, tmpName)
+}
+
+// run executes the command. If stdin is nil, the file is given as [[PATH]]
+func (ac *ActionCommand) run(ctx context.Context, stdin io.Reader, filename string) (*ResultV2, error) {
+	path := filename
+	if ac.wsl {
+		path = pathToWSL(filename)
+	}
+	cmdparam := make([]string, 0, len(ac.args))
+	for _, arg := range ac.args {
+		arg = strings.ReplaceAll(arg, EACTPlaceholderPath, path)
+		arg = strings.ReplaceAll(arg, EACTPlaceholderFilename, filepath.Base(filename))
+		cmdparam = append(cmdparam, arg)
+	}
+	cmdfile := ac.command
+	if ac.wsl {
+		cmdparam = append([]string{cmdfile}, cmdparam...)
+		cmdfile = "wsl"
+	}
+
+	var out, errOut bytes.Buffer
+	ctx, cancel := context.WithTimeout(ctx, ac.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, cmdfile, cmdparam...)
+	cmd.WaitDelay = commandWaitDelay
+	cmd.Stdin = stdin
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+
+	// some tools use the exit code for the validation result, so the output is parsed anyway
+	var exitMessage string
+	if err := runCommand(ctx, cmd); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || ctx.Err() != nil {
+			return nil, errors.Wrapf(commandError(ctx, err), "error executing (%s %s) for file '%s': %v", cmdfile, cmdparam, filename, errOut.String())
+		}
+		exitMessage = fmt.Sprintf("exit code %d: %s", exitErr.ExitCode(), strings.TrimSpace(errOut.String()))
+	}
+
+	data, err := ac.parse(out.Bytes())
+	if err != nil {
+		if exitMessage != "" {
+			return nil, errors.Wrapf(err, "error executing (%s %s) for file '%s': %s", cmdfile, cmdparam, filename, exitMessage)
+		}
+		return nil, errors.Wrapf(err, "cannot parse output of (%s %s) for file '%s'", cmdfile, cmdparam, filename)
+	}
+	result := mapResult(ac.GetName(), ac.mapping, data)
+	if exitMessage != "" {
+		if msg, ok := result.Errors[ac.GetName()]; ok {
+			exitMessage = msg + "; " + exitMessage
+		}
+		result.Errors[ac.GetName()] = exitMessage
+		result.ActionErrors = append(result.ActionErrors, NewActionError(IndexerExitCode, ac.GetName(), exitMessage))
+	}
+	return result, nil
+}
+
+func (ac *ActionCommand) parse(output []byte) (any, error) {
+	switch ac.output {
+	case CommandOutputKeyValue:
+		var data = map[string]any{}
+		scanner := bufio.NewScanner(bytes.NewReader(output))
+		for scanner.Scan() {
+			key, value, found := strings.Cut(scanner.Text(), ac.separator)
+			if !found {
+				continue
+			}
+			if key = strings.TrimSpace(key); key != "" {
+				data[key] = strings.TrimSpace(value)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, errors.Wrap(err, "cannot read output")
+		}
+		return data, nil
+	case CommandOutputRegexp:
+		// named groups of the first match
+		var data = map[string]any{}
+		matches := ac.regexp.FindSubmatch(output)
+		if matches == nil {
+			return data, nil
+		}
+		for i, name := range ac.regexp.SubexpNames() {
+			if name != "" && matches[i] != nil {
+				data[name] = string(matches[i])
+			}
+		}
+		return data, nil
+	default:
+		var data any
+		if err := json.Unmarshal(output, &data); err != nil {
+			return nil, errors.Wrapf(err, "cannot unmarshal json: %s", string(output))
+		}
+		return data, nil
+	}
+}
+
+func (ac *ActionCommand) GetWeight() uint {
+	return ac.weight
+}
+
+func (ac *ActionCommand) GetCaps() ActionCapability {
+	return ac.caps
+}
+
+func (ac *ActionCommand) GetName() string {
+	return ac.name
+}
+
+var (
+	_ Action        = &ActionCommand{}
+	_ ContextAction = &ActionCommand{}
+)
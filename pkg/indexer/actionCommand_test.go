@@ -0,0 +1,155 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestActionCommandHelper is called as command by TestActionCommand
+func TestActionCommandHelper(t *testing.T) {
+	if os.Getenv("INDEXER_COMMAND_HELPER") != "1" {
+		return
+	}
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	if len(args) < 2 {
+		os.Exit(2)
+	}
+	var data []byte
+	if len(args) > 2 {
+		data, _ = os.ReadFile(args[2])
+	} else {
+		data, _ = io.ReadAll(os.Stdin)
+	}
+	switch args[1] {
+	case "json":
+		fmt.Printf(`[{"MIMEType": "text/x-test", "ImageWidth": %d}]`, len(data))
+	case "keyvalue":
+		fmt.Printf("Pages:  %d\nProducer: test\n", len(data))
+	case "regexp":
+		fmt.Printf("size %d bytes, format text/x-test\n", len(data))
+	case "sleep":
+		time.Sleep(time.Minute)
+	case "invalid":
+		fmt.Print("invalid document")
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestActionCommand(t *testing.T) {
+	t.Setenv("INDEXER_COMMAND_HELPER", "1")
+	filename := filepath.Join(t.TempDir(), "test.txt")
+	assert.NoError(t, os.WriteFile(filename, []byte("0123456789"), 0644))
+
+	tests := []struct {
+		name       string
+		mode       string
+		input      string
+		separator  string
+		regexp     string
+		mapping    map[string]string
+		wantMime   string
+		wantWidth  uint
+		wantMeta   any
+		wantErrors bool
+	}{
+		{name: "json stdin", mode: "json", input: CommandInputStdin, mapping: map[string]string{"mimetype": "0.MIMEType", "width": "0.ImageWidth"}, wantMime: "text/x-test", wantWidth: 10},
+		{name: "json file", mode: "json", input: CommandInputFile, mapping: map[string]string{"mimetype": "0.MIMEType", "width": "0.ImageWidth"}, wantMime: "text/x-test", wantWidth: 10},
+		{name: "keyvalue", mode: "keyvalue", input: CommandInputFile, separator: ":", wantMeta: map[string]any{"Pages": "10", "Producer": "test"}},
+		{name: "regexp", mode: "regexp", input: CommandInputStdin, regexp: `size (?P<size>\d+) bytes, format (?P<mime>\S+)`, mapping: map[string]string{"mimetype": "mime", "width": "size"}, wantMime: "text/x-test", wantWidth: 10},
+		{name: "exit code", mode: "invalid", input: CommandInputStdin, regexp: `(?P<message>.+)`, wantMeta: map[string]any{"message": "invalid document"}, wantErrors: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := tt.mode
+			if tt.mode == "invalid" {
+				output = CommandOutputRegexp
+			}
+			args := []string{"-test.run=^TestActionCommandHelper$", "--", tt.mode}
+			if tt.input == CommandInputFile {
+				args = append(args, "[[PATH]]")
+			}
+			ad := NewActionDispatcher(nil)
+			action, err := NewActionCommand("cmd", os.Args[0], args, false, tt.input, output, tt.separator, tt.regexp, tt.mapping, "", 0, 0, t.TempDir(), ad)
+			assert.NoError(t, err)
+			for _, stream := range []bool{false, true} {
+				var result *ResultV2
+				if stream {
+					result, err = action.Stream("", strings.NewReader("0123456789"), "test.txt")
+				} else {
+					result, err = action.DoV2(filename)
+				}
+				if !assert.NoError(t, err) {
+					continue
+				}
+				assert.Equal(t, tt.wantMime, result.Mimetype)
+				assert.Equal(t, tt.wantWidth, result.Width)
+				if tt.wantMeta != nil {
+					assert.Equal(t, tt.wantMeta, result.Metadata["cmd"])
+				}
+				assert.Equal(t, tt.wantErrors, result.Errors["cmd"] != "")
+			}
+		})
+	}
+}
+
+func TestActionCommandCancel(t *testing.T) {
+	t.Setenv("INDEXER_COMMAND_HELPER", "1")
+	ad := NewActionDispatcher(nil)
+	args := []string{"-test.run=^TestActionCommandHelper$", "--", "sleep", "[[PATH]]"}
+	_, err := NewActionCommand("cmd", os.Args[0], args, false, CommandInputFile, CommandOutputJSON, "", "", nil, "", 0, 0, t.TempDir(), ad)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = ad.StreamContext(ctx, strings.NewReader("0123456789"), []string{"test.txt"}, []string{"cmd"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestActionCommandErrors(t *testing.T) {
+	t.Setenv("INDEXER_COMMAND_HELPER", "1")
+	tests := []struct {
+		name          string
+		command       string
+		mode          string
+		output        string
+		wantID        errorID
+		wantRetriable bool
+	}{
+		{name: "tool not found", command: filepath.Join(t.TempDir(), "missing"), mode: "json", output: CommandOutputJSON, wantID: IndexerToolNotFound},
+		{name: "timeout", command: os.Args[0], mode: "sleep", output: CommandOutputJSON, wantID: IndexerTimeout, wantRetriable: true},
+		{name: "exit code", command: os.Args[0], mode: "invalid", output: CommandOutputKeyValue, wantID: IndexerExitCode},
+		{name: "output", command: os.Args[0], mode: "keyvalue", output: CommandOutputJSON, wantID: IndexerOutputParse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ad := NewActionDispatcher(nil)
+			args := []string{"-test.run=^TestActionCommandHelper$", "--", tt.mode}
+			_, err := NewActionCommand("cmd", tt.command, args, false, CommandInputStdin, tt.output, ":", "", nil, "", 0, 500*time.Millisecond, t.TempDir(), ad)
+			assert.NoError(t, err)
+			result, err := ad.Stream(strings.NewReader("0123456789"), []string{"test.txt"}, []string{"cmd"})
+			if !assert.NoError(t, err) || !assert.Len(t, result.ActionErrors, 1) {
+				return
+			}
+			actionErr := result.ActionErrors[0]
+			assert.Equal(t, tt.wantID, actionErr.ID)
+			assert.Equal(t, "cmd", actionErr.Action)
+			assert.Equal(t, tt.wantRetriable, actionErr.Retriable)
+			assert.NotZero(t, actionErr.Severity)
+			assert.Equal(t, result.Errors["cmd"], actionErr.Message)
+		})
+	}
+}
@@ -0,0 +1,353 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package indexer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/hooklift/iso9660"
+)
+
+const (
+	ContainerZIP = "zip"
+	ContainerTAR = "tar"
+	ContainerISO = "iso"
+)
+
+const (
+	containerDefaultMaxDepth   = 3
+	containerDefaultMaxMembers = 10000
+	containerDefaultMaxSize    = 4 * 1024 * 1024 * 1024
+	// the iso9660 signature is at the beginning of sector 16
+	containerPeekSize = 16*2048 + 6
+)
+
+var (
+	ErrContainerMaxMembers = errors.New("max. number of container members exceeded")
+	ErrContainerMaxSize    = errors.New("max. expanded container size exceeded")
+)
+
+// ContainerMember is the result of one file within a container
+type ContainerMember struct {
+	Path   string    `json:"path"`
+	Error  string    `json:"error,omitempty"`
+	Result *ResultV2 `json:"result,omitempty"`
+}
+
+// ContainerResult is the list of all members of a container.
+// Members which are containers contain the nested ContainerResult in their metadata.
+type ContainerResult struct {
+	Format  string             `json:"format"`
+	Members []*ContainerMember `json:"members"`
+}
+
+// containerState holds the limits of one top level container
+type containerState struct {
+	members int
+	size    int64
+}
+
+// NewActionContainer creates an action, which runs the given actions on all members of ZIP, TAR and ISO containers.
+// 7z is not supported. Without actions, all stream actions are used. Nested containers are opened up to maxDepth levels.
+func NewActionContainer(name string, actions []string, maxDepth int, maxMembers int, maxSize int64, tempDir string, ad *ActionDispatcher) Action {
+	if maxDepth <= 0 {
+		maxDepth = containerDefaultMaxDepth
+	}
+	if maxMembers <= 0 {
+		maxMembers = containerDefaultMaxMembers
+	}
+	if maxSize <= 0 {
+		maxSize = containerDefaultMaxSize
+	}
+	ac := &ActionContainer{
+		name:       name,
+		actions:    actions,
+		maxDepth:   maxDepth,
+		maxMembers: maxMembers,
+		maxSize:    maxSize,
+		tempDir:    tempDir,
+		ad:         ad,
+		caps:       ACTFILEFULL | ACTSTREAM,
+	}
+	ad.RegisterAction(ac)
+	return ac
+}
+
+type ActionContainer struct {
+	name       string
+	actions    []string
+	maxDepth   int
+	maxMembers int
+	maxSize    int64
+	tempDir    string
+	ad         *ActionDispatcher
+	caps       ActionCapability
+}
+
+// detectContainer checks the signatures of the supported container formats
+func detectContainer(br *bufio.Reader) string {
+	head, _ := br.Peek(containerPeekSize)
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")) || bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		return ContainerZIP
+	case len(head) >= 262 && string(head[257:262]) == "ustar":
+		return ContainerTAR
+	case len(head) >= containerPeekSize && string(head[16*2048+1:16*2048+6]) == "CD001":
+		return ContainerISO
+	}
+	return ""
+}
+
+// memberActions returns the actions for the container members without the container action itself
+func (ac *ActionContainer) memberActions() []string {
+	actions := ac.actions
+	if len(actions) == 0 {
+		actions = ac.ad.GetActionNamesByCaps(ACTSTREAM)
+	}
+	return slices.DeleteFunc(slices.Clone(actions), func(name string) bool { return name == ac.name })
+}
+
+func (ac *ActionContainer) Stream(contentType string, reader io.Reader, filename string) (*ResultV2, error) {
+	return ac.StreamContext(context.Background(), contentType, reader, filename)
+}
+
+func (ac *ActionContainer) StreamContext(ctx context.Context, contentType string, reader io.Reader, filename string) (*ResultV2, error) {
+	br := bufio.NewReaderSize(reader, containerPeekSize)
+	format := detectContainer(br)
+	var result = NewResultV2()
+	if format == "" {
+		return result, nil
+	}
+	cr, err := ac.open(ctx, format, br, 1, ac.memberActions(), &containerState{})
+	if err != nil {
+		result.AddError(ac.GetName(), err)
+	}
+	result.Metadata[ac.GetName()] = cr
+	return result, nil
+}
+
+// open reads the container from reader. ZIP and ISO need random access and are spooled to a temporary file.
+func (ac *ActionContainer) open(ctx context.Context, format string, reader io.Reader, depth int, actions []string, state *containerState) (*ContainerResult, error) {
+	cr := &ContainerResult{Format: format, Members: []*ContainerMember{}}
+	if format == ContainerTAR {
+		tr := tar.NewReader(reader)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				return cr, nil
+			}
+			if err != nil {
+				return cr, errors.Wrap(err, "cannot read tar header")
+			}
+			if hdr.Typeflag != tar.TypeReg {
+				continue
+			}
+			if err := ac.member(ctx, cr, hdr.Name, tr, depth, actions, state); err != nil {
+				return cr, err
+			}
+		}
+	}
+
+	tmpFile, err := os.CreateTemp(ac.tempDir, "container-*")
+	if err != nil {
+		return cr, errors.Wrap(err, "cannot create temporary file")
+	}
+	tmpName := tmpFile.Name()
+	defer os.Remove(tmpName)
+	defer tmpFile.Close()
+	// the spooled container is not larger than the max. expanded size
+	size, err := io.Copy(tmpFile, io.LimitReader(reader, ac.maxSize+1))
+	if err != nil {
+		return cr, errors.Wrapf(err, "cannot write container to '%s'", tmpName)
+	}
+	if size > ac.maxSize {
+		return cr, errors.WithMessagef(ErrContainerMaxSize, "cannot spool container larger than %d bytes", ac.maxSize)
+	}
+
+	switch format {
+	case ContainerZIP:
+		zr, err := zip.NewReader(tmpFile, size)
+		if err != nil {
+			return cr, errors.Wrap(err, "cannot open zip")
+		}
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			fr, err := f.Open()
+			if err != nil {
+				cr.Members = append(cr.Members, &ContainerMember{Path: f.Name, Error: err.Error()})
+				continue
+			}
+			err = ac.member(ctx, cr, f.Name, fr, depth, actions, state)
+			fr.Close()
+			if err != nil {
+				return cr, err
+			}
+		}
+	case ContainerISO:
+		if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
+			return cr, errors.Wrapf(err, "cannot seek '%s'", tmpName)
+		}
+		ir, err := iso9660.NewReader(tmpFile)
+		if err != nil {
+			return cr, errors.Wrap(err, "cannot open iso image")
+		}
+		for {
+			fi, err := ir.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return cr, errors.Wrap(err, "cannot read iso image")
+			}
+			if fi.IsDir() {
+				continue
+			}
+			fr, ok := fi.Sys().(io.Reader)
+			if !ok {
+				continue
+			}
+			if err := ac.member(ctx, cr, strings.TrimPrefix(fi.Name(), "/"), fr, depth, actions, state); err != nil {
+				return cr, err
+			}
+		}
+	}
+	return cr, nil
+}
+
+// member indexes one container member and recurses into nested containers.
+// Only errors of the limits are returned.
+func (ac *ActionContainer) member(ctx context.Context, cr *ContainerResult, name string, reader io.Reader, depth int, actions []string, state *containerState) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	state.members++
+	if state.members > ac.maxMembers {
+		return ErrContainerMaxMembers
+	}
+	cm := &ContainerMember{Path: name}
+	cr.Members = append(cr.Members, cm)
+
+	lr := &containerLimitReader{r: reader, state: state, maxSize: ac.maxSize}
+	br := bufio.NewReaderSize(lr, containerPeekSize)
+	format := ""
+	if depth < ac.maxDepth {
+		format = detectContainer(br)
+	}
+	var dataReader io.Reader = br
+	var tmpFile *os.File
+	if format != "" {
+		var err error
+		if tmpFile, err = os.CreateTemp(ac.tempDir, "container-*"); err != nil {
+			cm.Error = errors.Wrap(err, "cannot create temporary file").Error()
+			return nil
+		}
+		defer os.Remove(tmpFile.Name())
+		defer tmpFile.Close()
+		dataReader = io.TeeReader(br, tmpFile)
+	}
+	result, err := ac.ad.StreamContext(ctx, dataReader, []string{name}, actions)
+	if lr.err != nil {
+		return lr.err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err != nil {
+		cm.Error = err.Error()
+		return nil
+	}
+	cm.Result = result
+	if tmpFile == nil {
+		return nil
+	}
+	if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
+		cm.Error = errors.Wrapf(err, "cannot seek '%s'", tmpFile.Name()).Error()
+		return nil
+	}
+	nested, err := ac.open(ctx, format, tmpFile, depth+1, actions, state)
+	result.Metadata[ac.GetName()] = nested
+	if errors.Is(err, ErrContainerMaxMembers) || errors.Is(err, ErrContainerMaxSize) || ctx.Err() != nil {
+		return err
+	}
+	if err != nil {
+		result.AddError(ac.GetName(), err)
+	}
+	return nil
+}
+
+// containerLimitReader counts the expanded size of all members of a container
+type containerLimitReader struct {
+	r       io.Reader
+	state   *containerState
+	maxSize int64
+	err     error
+}
+
+func (lr *containerLimitReader) Read(p []byte) (int, error) {
+	if lr.err != nil {
+		return 0, lr.err
+	}
+	n, err := lr.r.Read(p)
+	lr.state.size += int64(n)
+	if lr.state.size > lr.maxSize {
+		lr.err = ErrContainerMaxSize
+		return n, lr.err
+	}
+	return n, err
+}
+
+func (ac *ActionContainer) DoV2(filename string) (*ResultV2, error) {
+	return ac.DoV2Context(context.Background(), filename)
+}
+
+func (ac *ActionContainer) DoV2Context(ctx context.Context, filename string) (*ResultV2, error) {
+	fp, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open file '%s'", filename)
+	}
+	defer fp.Close()
+	return ac.StreamContext(ctx, "", fp, filename)
+}
+
+func (ac *ActionContainer) CanHandle(contentType string, filename string) bool {
+	return true
+}
+
+func (ac *ActionContainer) GetWeight() uint {
+	return 100
+}
+
+func (ac *ActionContainer) GetCaps() ActionCapability {
+	return ac.caps
+}
+
+func (ac *ActionContainer) GetName() string {
+	return ac.name
+}
+
+var (
+	_ Action        = &ActionContainer{}
+	_ ContextAction = &ActionContainer{}
+)
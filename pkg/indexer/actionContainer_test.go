@@ -0,0 +1,113 @@
+package indexer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/je4/utils/v2/pkg/checksum"
+	"github.com/stretchr/testify/assert"
+)
+
+func testZip(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	// sorted for a stable member order
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		data := files[name]
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write(data)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+// testRandom returns incompressible data
+func testRandom(t *testing.T, size int) []byte {
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func testTar(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	// sorted for a stable member order
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		data := files[name]
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg, Format: tar.FormatUSTAR}))
+		_, err := tw.Write(data)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func TestActionContainer(t *testing.T) {
+	inner := testZip(t, map[string][]byte{"inner.txt": []byte("hello world")})
+	outer := testTar(t, map[string][]byte{"a.txt": []byte("a"), "sub/inner.zip": inner})
+
+	tests := []struct {
+		name       string
+		data       []byte
+		maxDepth   int
+		maxMembers int
+		maxSize    int64
+		wantPaths  []string
+		wantNested bool
+		wantError  bool
+	}{
+		{name: "no container", data: []byte("plain text"), wantPaths: nil},
+		{name: "zip", data: inner, wantPaths: []string{"inner.txt"}},
+		{name: "nested", data: outer, wantPaths: []string{"a.txt", "sub/inner.zip"}, wantNested: true},
+		{name: "max depth", data: outer, maxDepth: 1, wantPaths: []string{"a.txt", "sub/inner.zip"}},
+		{name: "max members", data: outer, maxMembers: 2, wantPaths: []string{"a.txt", "sub/inner.zip"}, wantNested: true, wantError: true},
+		{name: "max size", data: testZip(t, map[string][]byte{"big.txt": bytes.Repeat([]byte{0}, 10000)}), maxSize: 1000, wantPaths: []string{"big.txt"}, wantError: true},
+		{name: "max spool size", data: testZip(t, map[string][]byte{"a.txt": []byte("a"), "random.bin": testRandom(t, 2000)}), maxSize: 1000, wantPaths: []string{}, wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ad := NewActionDispatcher(nil)
+			NewActionChecksum("checksum", []checksum.DigestAlgorithm{checksum.DigestMD5}, ad)
+			action := NewActionContainer("container", nil, tt.maxDepth, tt.maxMembers, tt.maxSize, t.TempDir(), ad)
+			result, err := action.Stream("", bytes.NewReader(tt.data), "test")
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantError, result.Errors["container"] != "")
+			if tt.wantPaths == nil {
+				assert.Nil(t, result.Metadata["container"])
+				return
+			}
+			cr, ok := result.Metadata["container"].(*ContainerResult)
+			if !assert.True(t, ok) {
+				return
+			}
+			var paths []string
+			for _, m := range cr.Members {
+				paths = append(paths, m.Path)
+			}
+			assert.ElementsMatch(t, tt.wantPaths, paths)
+			for _, m := range cr.Members {
+				if m.Path != "sub/inner.zip" || m.Result == nil {
+					continue
+				}
+				nested, ok := m.Result.Metadata["container"].(*ContainerResult)
+				assert.Equal(t, tt.wantNested, ok)
+				if ok && !tt.wantError {
+					assert.Equal(t, ContainerZIP, nested.Format)
+					assert.Equal(t, "inner.txt", nested.Members[0].Path)
+					assert.Equal(t, "5eb63bbbe01eeed093cb22bb8f5acdc3", nested.Members[0].Result.Checksum["md5"])
+				}
+			}
+		})
+	}
+}
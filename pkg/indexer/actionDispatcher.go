@@ -4,22 +4,230 @@ import (
 	"bufio"
 	"bytes"
 	"cmp"
+	"context"
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"emperror.dev/errors"
 	iou "github.com/je4/utils/v2/pkg/io"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/exp/slices"
 )
 
 type ActionDispatcher struct {
-	mimeRelevance []MimeWeight
-	actions       map[string]Action
+	mimeRelevance    []MimeWeight
+	actions          map[string]Action
+	cache            ResultCache
+	cacheFingerprint string
+	cacheVolatile    func(actions []string) (string, bool)
+	tempDir          string
+	localCache       bool
+	maxSpoolSize     int64
+	headerSize       int64
+	tailSize         int64
+	headerTimeout    time.Duration
+	fileMapper       *FileMapper
+	sftp             *SFTP
+	urlRegexp        []*regexp.Regexp
+	downloadMime     *regexp.Regexp
+	httpClient       *http.Client
+	metrics          *Metrics
+	tracerProvider   trace.TracerProvider
+}
+
+// defaultHeaderSize is used for head only indexing, if no header size is set
+const defaultHeaderSize = 100000
+
+// SetCache enables the result cache. The fingerprint identifies the configuration and tool versions,
+// stream data is spooled to tempDir to get the digest before indexing.
+func (ad *ActionDispatcher) SetCache(cache ResultCache, fingerprint string, tempDir string) {
+	ad.cache = cache
+	ad.cacheFingerprint = fingerprint
+	ad.tempDir = tempDir
+}
+
+// SetCacheVolatile sets the part of the fingerprint, which can change while running, e.g. the signature version of clamd.
+// It is called for every cache lookup, if it returns false, the result is neither read from nor written to the cache.
+func (ad *ActionDispatcher) SetCacheVolatile(volatile func(actions []string) (string, bool)) {
+	ad.cacheVolatile = volatile
+}
+
+// fingerprint returns the fingerprint of the cache key for actions or false, if the result must not be cached
+func (ad *ActionDispatcher) fingerprint(actions []string) (string, bool) {
+	if ad.cacheVolatile == nil {
+		return ad.cacheFingerprint, true
+	}
+	volatile, ok := ad.cacheVolatile(actions)
+	if !ok {
+		return "", false
+	}
+	return ad.cacheFingerprint + "\x00" + volatile, true
+}
+
+// SetTempDir sets the folder for temporary files
+func (ad *ActionDispatcher) SetTempDir(tempDir string) {
+	ad.tempDir = tempDir
+}
+
+// SetLocalCache enables spooling of streams to the temp folder for actions, which need the complete file.
+// Streams larger than maxSize (if > 0) are not spooled, all actions get the stream in one stage.
+func (ad *ActionDispatcher) SetLocalCache(enabled bool, maxSize int64) {
+	ad.localCache = enabled
+	ad.maxSpoolSize = maxSize
+}
+
+// SetHead configures the head only indexing. The first headerSize and the last tailSize bytes are read
+// within headerTimeout (if > 0).
+func (ad *ActionDispatcher) SetHead(headerSize int64, tailSize int64, headerTimeout time.Duration) {
+	ad.headerSize = headerSize
+	ad.tailSize = tailSize
+	ad.headerTimeout = headerTimeout
+	if ad.httpClient != nil {
+		ad.httpClient = newHTTPClient(headerTimeout)
+	}
+}
+
+// SetMetrics enables the instrumentation of the actions
+func (ad *ActionDispatcher) SetMetrics(metrics *Metrics) {
+	ad.metrics = metrics
+}
+
+// Metrics returns the metrics of the dispatcher or nil, if not enabled
+func (ad *ActionDispatcher) Metrics() *Metrics {
+	return ad.metrics
+}
+
+// HeadContext indexes only the head of reader with the actions, which can deal with the file head (ACTHEAD).
+// If reader is an io.ReaderAt and size >= 0, the tail of the data is appended to the head.
+// Without actions, all head actions are used. If not all data has been read, the result is marked as partial.
+func (ad *ActionDispatcher) HeadContext(ctx context.Context, reader io.Reader, size int64, stateFiles []string, actions []string) (result *ResultV2, err error) {
+	ctx, span := ad.startSpan(ctx, "Head", stateFiles, actions)
+	defer func() { endSpan(span, result, err) }()
+	if ad.headerTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ad.headerTimeout)
+		defer cancel()
+	}
+	if len(actions) == 0 {
+		actions = ad.GetActionNamesByCaps(ACTHEAD)
+	}
+	var headActions []string
+	for _, name := range actions {
+		action, ok := ad.actions[name]
+		if !ok {
+			return nil, errors.Errorf("action '%s' not configured", name)
+		}
+		if action.GetCaps()&ACTHEAD != 0 && action.GetCaps()&ACTSTREAM != 0 {
+			headActions = append(headActions, name)
+		}
+	}
+	headerSize := ad.headerSize
+	if headerSize <= 0 {
+		headerSize = defaultHeaderSize
+	}
+
+	var data []byte
+	partial := false
+	readerAt, ok := reader.(io.ReaderAt)
+	if ok && size >= 0 && ad.tailSize > 0 && size > headerSize+ad.tailSize {
+		data = make([]byte, headerSize+ad.tailSize)
+		if _, err := readerAt.ReadAt(data[:headerSize], 0); err != nil {
+			return nil, errors.Wrapf(err, "cannot read head of %s", stateFiles)
+		}
+		if _, err := readerAt.ReadAt(data[headerSize:], size-ad.tailSize); err != nil && err != io.EOF {
+			return nil, errors.Wrapf(err, "cannot read tail of %s", stateFiles)
+		}
+		partial = true
+	} else {
+		// one more byte shows, whether there is more data
+		buf := make([]byte, headerSize+1)
+		n, err := io.ReadFull(&contextReader{ctx: ctx, r: reader}, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, errors.Wrapf(err, "cannot read head of %s", stateFiles)
+		}
+		partial = int64(n) > headerSize
+		data = buf[:min(int64(n), headerSize)]
+	}
+	result, err = ad.stream(ctx, bytes.NewReader(data), stateFiles, headActions, nil)
+	if err != nil {
+		return nil, err
+	}
+	result.Partial = partial
+	if size >= 0 {
+		result.Size = uint64(size)
+	} else if partial {
+		// the size is unknown
+		result.Size = 0
+	}
+	return result, nil
+}
+
+// needsFile returns true for stream actions, which work better on the complete local file
+func needsFile(action Action) bool {
+	caps := action.GetCaps()
+	return caps&ACTFILE != 0 && caps&ACTHEAD == 0
+}
+
+// spoolStream writes reader to a temporary file with the extension of filename.
+// If maxSize > 0, at most maxSize+1 bytes are written and the rest remains in reader.
+func spoolStream(reader io.Reader, tempDir string, filename string, maxSize int64) (*os.File, int64, error) {
+	tmpFile, err := os.CreateTemp(tempDir, "spool-*"+filepath.Ext(filename))
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "cannot create temporary file")
+	}
+	if maxSize > 0 {
+		reader = io.LimitReader(reader, maxSize+1)
+	}
+	size, err := io.Copy(tmpFile, reader)
+	if err == nil {
+		_, err = tmpFile.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		return nil, 0, errors.Wrapf(err, "cannot spool to '%s'", tmpFile.Name())
+	}
+	return tmpFile, size, nil
+}
+
+// cached returns the cached result of key or calls do and stores its result, if there are no errors
+func (ad *ActionDispatcher) cached(key string, do func() (*ResultV2, error)) (*ResultV2, error) {
+	result, err := ad.cache.Get(key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read result cache")
+	}
+	if result != nil {
+		// maps may be omitted in json
+		empty := NewResultV2()
+		if result.Errors == nil {
+			result.Errors = empty.Errors
+		}
+		if result.Checksum == nil {
+			result.Checksum = empty.Checksum
+		}
+		if result.Metadata == nil {
+			result.Metadata = empty.Metadata
+		}
+		return result, nil
+	}
+	result, err = do()
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Errors) == 0 {
+		if err := ad.cache.Set(key, result); err != nil {
+			return nil, errors.Wrapf(err, "cannot write result cache")
+		}
+	}
+	return result, nil
 }
 
 func NewActionDispatcher(mimeRelevance map[int]MimeWeightString) *ActionDispatcher {
@@ -88,7 +296,214 @@ func (ad *ActionDispatcher) GetActionNamesByCaps(caps ActionCapability) []string
 	return names
 }
 
+// requestedActions returns the configured actions of the given names
+func (ad *ActionDispatcher) requestedActions(actions []string) []Action {
+	var result []Action
+	for _, name := range actions {
+		if action, ok := ad.actions[name]; ok {
+			result = append(result, action)
+		}
+	}
+	return result
+}
+
+// useResult runs the result consumers on the merged result
+func useResult(result *ResultV2, consumers []Action) {
+	for _, action := range consumers {
+		r, err := action.(ResultConsumer).UseResult(result)
+		if err != nil {
+			r = NewResultV2()
+			r.AddError(action.GetName(), err)
+		}
+		result.Merge(r)
+	}
+}
+
+// contextReader stops reading, if the context is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := cr.r.Read(p)
+	// MimeReader drops data, which is returned together with io.EOF (e.g. by http bodies)
+	if n > 0 && err == io.EOF {
+		err = nil
+	}
+	return n, err
+}
+
+// countReader counts the bytes read
+type countReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// streamAction calls the context aware variant of the action, if available
+func (ad *ActionDispatcher) streamAction(ctx context.Context, action Action, contentType string, reader io.Reader, filename string) (result *ResultV2, err error) {
+	done := ad.metrics.start(action.GetName())
+	ctx, span := startChildSpan(ctx, action.GetName(), attribute.String("indexer.action", action.GetName()), attribute.String("indexer.contenttype", contentType))
+	cr := &countReader{r: reader}
+	defer func() {
+		done(actionStatus(action.GetName(), result, err))
+		span.SetAttributes(attribute.Int64("indexer.bytes", cr.n))
+		endActionSpan(span, action.GetName(), result, err)
+	}()
+	if ca, ok := action.(ContextAction); ok {
+		return ca.StreamContext(ctx, contentType, cr, filename)
+	}
+	return action.Stream(contentType, cr, filename)
+}
+
+// doV2Action calls the context aware variant of the action, if available
+func (ad *ActionDispatcher) doV2Action(ctx context.Context, action Action, filename string) (result *ResultV2, err error) {
+	done := ad.metrics.start(action.GetName())
+	ctx, span := startChildSpan(ctx, action.GetName(), attribute.String("indexer.action", action.GetName()))
+	defer func() {
+		done(actionStatus(action.GetName(), result, err))
+		endActionSpan(span, action.GetName(), result, err)
+	}()
+	if ca, ok := action.(ContextAction); ok {
+		return ca.DoV2Context(ctx, filename)
+	}
+	return action.DoV2(filename)
+}
+
 func (ad *ActionDispatcher) Stream(sourceReader io.Reader, stateFiles []string, actions []string) (*ResultV2, error) {
+	return ad.StreamContext(context.Background(), sourceReader, stateFiles, actions)
+}
+
+// StreamContext runs the actions on the data of sourceReader.
+// If ctx is done, reading is stopped, the running tools are killed and the error of ctx is returned.
+func (ad *ActionDispatcher) StreamContext(ctx context.Context, sourceReader io.Reader, stateFiles []string, actions []string) (result *ResultV2, err error) {
+	ctx, span := ad.startSpan(ctx, "Stream", stateFiles, actions)
+	defer func() { endSpan(span, result, err) }()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sourceReader = &contextReader{ctx: ctx, r: sourceReader}
+	if ad.cache == nil {
+		return ad.stream(ctx, sourceReader, stateFiles, actions, nil)
+	}
+	fingerprint, ok := ad.fingerprint(actions)
+	if !ok {
+		return ad.stream(ctx, sourceReader, stateFiles, actions, nil)
+	}
+	var filename string
+	if len(stateFiles) > 0 {
+		filename = stateFiles[0]
+	}
+	tmpFile, digest, err := spool(sourceReader, ad.tempDir, filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot spool %s", stateFiles)
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+	return ad.cached(resultCacheKey(digest, fingerprint, filename, actions), func() (*ResultV2, error) {
+		return ad.stream(ctx, &contextReader{ctx: ctx, r: tmpFile}, stateFiles, actions, tmpFile)
+	})
+}
+
+// stages splits the requested actions into identification actions, characterisation actions and result consumers.
+// Actions without ACTSTREAM are only allowed for local files.
+func (ad *ActionDispatcher) stages(actions []string, local bool) (ident, other, consumers []Action, err error) {
+	requested := ad.requestedActions(actions)
+	for _, name := range actions {
+		action, ok := ad.actions[name]
+		if !ok {
+			return nil, nil, nil, errors.Errorf("action '%s' not configured", name)
+		}
+		if action.GetCaps()&ACTSTREAM == 0 && !(local && action.GetCaps()&ACTFILE != 0) {
+			return nil, nil, nil, errors.Errorf("action '%s' needs a file or URL", name)
+		}
+		if rc, ok := action.(ResultConsumer); ok && rc.CanUseResult(requested) {
+			consumers = append(consumers, action)
+			continue
+		}
+		if action.GetCaps()&ACTIDENT != 0 {
+			ident = append(ident, action)
+		} else {
+			other = append(other, action)
+		}
+	}
+	return ident, other, consumers, nil
+}
+
+// mimeWeights returns the relevance of the mimetypes, 50 if no relevance is configured
+func (ad *ActionDispatcher) mimeWeights(mimetypes []string) map[string]int {
+	mimeMap := map[string]int{}
+	for _, mimetype := range mimetypes {
+		mimeMap[mimetype] = 50
+		for _, mr := range ad.mimeRelevance {
+			if mr.regexp.MatchString(mimetype) {
+				mimeMap[mimetype] = mr.weight
+				break
+			}
+		}
+	}
+	return mimeMap
+}
+
+// sortMimetypes sorts the mimetypes by relevance, the most relevant first
+func (ad *ActionDispatcher) sortMimetypes(mimetypes []string) {
+	mimeMap := ad.mimeWeights(mimetypes)
+	slices.SortStableFunc(mimetypes, func(a, b string) int {
+		// higher weight means less in sorting
+		return -cmp.Compare(mimeMap[a], mimeMap[b])
+	})
+}
+
+type pronomsKey struct{}
+
+// withPronoms passes the pronoms of the identification actions to the characterisation actions
+func withPronoms(ctx context.Context, pronoms []string) context.Context {
+	return context.WithValue(ctx, pronomsKey{}, slices.Clone(pronoms))
+}
+
+// stagePronoms returns the pronoms of the identification actions or nil, if there was no identification stage
+func stagePronoms(ctx context.Context) []string {
+	pronoms, _ := ctx.Value(pronomsKey{}).([]string)
+	return pronoms
+}
+
+// canHandle asks the action, if it can handle the content type or the file.
+// PronomActions are asked for the pronoms of the identification stage, too.
+func canHandle(ctx context.Context, action Action, contentType string, filename string) bool {
+	if action.CanHandle(contentType, filename) {
+		return true
+	}
+	pa, ok := action.(PronomAction)
+	return ok && pa.CanHandlePronoms(stagePronoms(ctx))
+}
+
+// stageContentType returns the most relevant mimetype of the identification result.
+// The detected contentType is used only, if nothing has been identified.
+func (ad *ActionDispatcher) stageContentType(result *ResultV2, contentType string) string {
+	mimetypes := slices.DeleteFunc(slices.Clone(result.Mimetypes), func(mimetype string) bool {
+		return mimetype == "" || mimetype == contentType
+	})
+	slices.Sort(mimetypes)
+	mimetypes = slices.Compact(mimetypes)
+	ad.sortMimetypes(mimetypes)
+	if len(mimetypes) > 0 {
+		return mimetypes[0]
+	}
+	return contentType
+}
+
+// stream runs the actions on sourceReader. If the data of sourceReader has already been spooled
+// to the file spooled, this file is used for the stages and the local cache.
+func (ad *ActionDispatcher) stream(ctx context.Context, sourceReader io.Reader, stateFiles []string, actions []string, spooled *os.File) (*ResultV2, error) {
 
 	if len(stateFiles) == 0 {
 		stateFiles = []string{""}
@@ -101,75 +516,79 @@ func (ad *ActionDispatcher) Stream(sourceReader io.Reader, stateFiles []string,
 	parts := strings.Split(contentType, ";")
 	contentType = parts[0]
 
-	var actionWriters = []*iou.WriteIgnoreCloser{}
-	var wg = sync.WaitGroup{}
-	results := make(chan *ResultV2, len(ad.actions))
-	for _, actionStr := range actions {
-		var found bool
-		for _, action := range ad.actions {
-			if actionStr == action.GetName() && action.GetCaps()&ACTSTREAM != 0 {
-				found = true
-				if contentType != "applictation/octet-stream" && !action.CanHandle(contentType, stateFiles[0]) {
-					break
-				}
-				wg.Add(1)
-				pr, pw := io.Pipe()
-				actionWriters = append(actionWriters, iou.NewWriteIgnoreCloser(pw))
-				go func(actionReader io.Reader, a Action) {
-					defer wg.Done()
-					// stream to actions
-					result, err := a.Stream(contentType, actionReader, stateFiles[0])
-					if err != nil {
-						result = NewResultV2()
-						result.Errors[a.GetName()] = err.Error()
-					}
-					// send result to channel
-					if result != nil {
-						results <- result
-					}
-					// discard remaining data
-					_, _ = io.Copy(io.Discard, actionReader)
-				}(iou.NewReadIgnoreCloser(pr), action)
-				break
+	ident, other, consumers, err := ad.stages(actions, false)
+	if err != nil {
+		return nil, err
+	}
+	// the characterisation actions need the data a second time,
+	// actions which need the complete file get it from the local cache
+	staged := len(ident) > 0 && len(other) > 0
+	spool := staged || (ad.localCache && slices.ContainsFunc(other, needsFile))
+	var reader io.Reader = mimeReader
+	var localFile string
+	var tmpFile *os.File
+	if spool {
+		var size int64
+		if spooled != nil {
+			// the complete data is in the spool file of the result cache
+			tmpFile = spooled
+			info, err := tmpFile.Stat()
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot stat '%s'", tmpFile.Name())
 			}
+			size = info.Size()
+			if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
+				return nil, errors.Wrapf(err, "cannot seek '%s'", tmpFile.Name())
+			}
+		} else {
+			if tmpFile, size, err = spoolStream(mimeReader, ad.tempDir, stateFiles[0], ad.maxSpoolSize); err != nil {
+				return nil, errors.Wrapf(err, "cannot spool %s", stateFiles)
+			}
+			defer os.Remove(tmpFile.Name())
+			defer tmpFile.Close()
 		}
-		if !found {
-			return nil, errors.Errorf("action '%s' not configured", actionStr)
+		reader = &contextReader{ctx: ctx, r: tmpFile}
+		if ad.maxSpoolSize <= 0 || size <= ad.maxSpoolSize {
+			if ad.localCache {
+				localFile = tmpFile.Name()
+			}
+		} else if spooled == nil {
+			// too large to spool, the data is streamed once to all actions
+			reader = io.MultiReader(reader, mimeReader)
+			staged = false
 		}
 	}
-	var actionBufferWriters = []io.Writer{}
-	for _, w := range actionWriters {
-		actionBufferWriters = append(actionBufferWriters, bufio.NewWriterSize(w, 1024*1024))
-		//		ws = append(ws, w)
-	}
-	multiWriter := io.MultiWriter(actionBufferWriters...)
-	errorList := []error{}
-	written, err := io.Copy(multiWriter, mimeReader)
-	if err != nil {
-		errorList = append(errorList, errors.Wrap(err, "cannot copy mimereader to actionwriter"))
-	}
-	for _, actionBufferWriter := range actionBufferWriters {
-		// it's sure, that w is a bufio.Writer
-		if err := actionBufferWriter.(*bufio.Writer).Flush(); err != nil {
-			errorList = append(errorList, errors.Wrap(err, "cannot flush buffer"))
+
+	var result *ResultV2
+	var written int64
+	stageType := contentType
+	if !staged {
+		if result, written, err = ad.streamStage(ctx, reader, contentType, stateFiles, append(ident, other...), localFile); err != nil {
+			return nil, err
 		}
-	}
-	for _, w := range actionWriters {
-		if err := w.ForceClose(); err != nil {
-			errorList = append(errorList, errors.Wrap(err, "cannot close buffer"))
+		if len(ident) > 0 {
+			stageType = ad.stageContentType(result, contentType)
+		}
+	} else {
+		// the identification actions read the data first, the characterisation actions
+		// get the identified mimetype
+		if result, written, err = ad.streamStage(ctx, reader, contentType, stateFiles, ident, localFile); err != nil {
+			return nil, err
+		}
+		if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
+			return nil, errors.Wrapf(err, "cannot seek '%s'", tmpFile.Name())
+		}
+		stageType = ad.stageContentType(result, contentType)
+		r, _, err := ad.streamStage(withPronoms(ctx, result.Pronoms), reader, stageType, stateFiles, other, localFile)
+		if err != nil {
+			return nil, err
 		}
-	}
-	// error of copy
-	if len(errorList) > 0 {
-		return nil, errors.Wrap(errors.Combine(errorList...), "cannot copy stream to actions")
-	}
-	// wait for all actions to finish
-	wg.Wait()
-	close(results)
-	result := NewResultV2()
-	for r := range results {
 		result.Merge(r)
 	}
+	consumers = slices.DeleteFunc(consumers, func(action Action) bool {
+		return !action.CanHandle(stageType, stateFiles[0])
+	})
+	useResult(result, consumers)
 
 	// sort mimetypes by weight
 	slices.Sort(result.Mimetypes)
@@ -177,20 +596,7 @@ func (ad *ActionDispatcher) Stream(sourceReader io.Reader, stateFiles []string,
 	if len(result.Mimetypes) == 0 && contentType != "" {
 		result.Mimetypes = []string{contentType}
 	}
-	mimeMap := map[string]int{}
-	for _, mimetype := range result.Mimetypes {
-		mimeMap[mimetype] = 50
-		for _, mr := range ad.mimeRelevance {
-			if mr.regexp.MatchString(mimetype) {
-				mimeMap[mimetype] = mr.weight
-				break
-			}
-		}
-	}
-	slices.SortFunc(result.Mimetypes, func(a, b string) int {
-		// higher weight means less in sorting
-		return -cmp.Compare(mimeMap[a], mimeMap[b])
-	})
+	ad.sortMimetypes(result.Mimetypes)
 	if len(result.Mimetypes) > 0 {
 		result.Mimetype = result.Mimetypes[0]
 	}
@@ -212,10 +618,125 @@ func (ad *ActionDispatcher) Stream(sourceReader io.Reader, stateFiles []string,
 	}
 
 	result.Size = uint64(written)
+	ad.metrics.streamed(written)
+	ad.metrics.identified(result)
 	return result, nil
 }
 
+// streamStage streams the data of reader to the given actions in parallel and merges their results.
+// If localFile is not empty, actions which need the complete file get localFile instead of the stream.
+func (ad *ActionDispatcher) streamStage(ctx context.Context, reader io.Reader, contentType string, stateFiles []string, actions []Action, localFile string) (*ResultV2, int64, error) {
+	var actionWriters = []*iou.WriteIgnoreCloser{}
+	var wg = sync.WaitGroup{}
+	results := make(chan *ResultV2, len(actions))
+	for _, action := range actions {
+		if contentType != "applictation/octet-stream" && !canHandle(ctx, action, contentType, stateFiles[0]) {
+			ad.metrics.skipped(action.GetName())
+			continue
+		}
+		wg.Add(1)
+		if localFile != "" && needsFile(action) {
+			go func(a Action) {
+				defer wg.Done()
+				result, err := ad.doV2Action(ctx, a, localFile)
+				if err != nil {
+					result = NewResultV2()
+					result.AddError(a.GetName(), err)
+				}
+				if result != nil {
+					results <- result
+				}
+			}(action)
+			continue
+		}
+		pr, pw := io.Pipe()
+		actionWriters = append(actionWriters, iou.NewWriteIgnoreCloser(pw))
+		go func(actionReader io.Reader, a Action) {
+			defer wg.Done()
+			// stream to actions
+			result, err := ad.streamAction(ctx, a, contentType, actionReader, stateFiles[0])
+			if err != nil {
+				result = NewResultV2()
+				result.AddError(a.GetName(), err)
+			}
+			// send result to channel
+			if result != nil {
+				results <- result
+			}
+			// discard remaining data
+			_, _ = io.Copy(io.Discard, actionReader)
+		}(iou.NewReadIgnoreCloser(pr), action)
+	}
+	var actionBufferWriters = []io.Writer{}
+	for _, w := range actionWriters {
+		actionBufferWriters = append(actionBufferWriters, bufio.NewWriterSize(w, 1024*1024))
+	}
+	multiWriter := io.MultiWriter(actionBufferWriters...)
+	errorList := []error{}
+	written, err := io.Copy(multiWriter, reader)
+	if err != nil {
+		errorList = append(errorList, errors.Wrap(err, "cannot copy mimereader to actionwriter"))
+	}
+	for _, actionBufferWriter := range actionBufferWriters {
+		// it's sure, that w is a bufio.Writer
+		if err := actionBufferWriter.(*bufio.Writer).Flush(); err != nil {
+			errorList = append(errorList, errors.Wrap(err, "cannot flush buffer"))
+		}
+	}
+	for _, w := range actionWriters {
+		if err := w.ForceClose(); err != nil {
+			errorList = append(errorList, errors.Wrap(err, "cannot close buffer"))
+		}
+	}
+	// wait for all actions to finish, even on errors, because they may still read localFile
+	wg.Wait()
+	close(results)
+	if err := ctx.Err(); err != nil {
+		return nil, 0, errors.Wrapf(err, "indexing of %s aborted", stateFiles)
+	}
+	// error of copy
+	if len(errorList) > 0 {
+		return nil, 0, errors.Wrap(errors.Combine(errorList...), "cannot copy stream to actions")
+	}
+	result := NewResultV2()
+	for r := range results {
+		result.Merge(r)
+	}
+	return result, written, nil
+}
+
 func (ad *ActionDispatcher) DoV2(filename string, stateFiles []string, actions []string) (*ResultV2, error) {
+	return ad.DoV2Context(context.Background(), filename, stateFiles, actions)
+}
+
+// DoV2Context runs the actions on filename. If ctx is done, the running tools are killed and the error of ctx is returned.
+func (ad *ActionDispatcher) DoV2Context(ctx context.Context, filename string, stateFiles []string, actions []string) (result *ResultV2, err error) {
+	ctx, span := ad.startSpan(ctx, "DoV2", stateFiles, actions)
+	defer func() { endSpan(span, result, err) }()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if ad.cache == nil {
+		return ad.doV2(ctx, filename, stateFiles, actions)
+	}
+	fingerprint, ok := ad.fingerprint(actions)
+	if !ok {
+		return ad.doV2(ctx, filename, stateFiles, actions)
+	}
+	digest, err := fileDigest(filename)
+	if err != nil {
+		return nil, err
+	}
+	name := filename
+	if len(stateFiles) > 0 && stateFiles[0] != "" {
+		name = stateFiles[0]
+	}
+	return ad.cached(resultCacheKey(digest, fingerprint, name, actions), func() (*ResultV2, error) {
+		return ad.doV2(ctx, filename, stateFiles, actions)
+	})
+}
+
+func (ad *ActionDispatcher) doV2(ctx context.Context, filename string, stateFiles []string, actions []string) (*ResultV2, error) {
 	if len(stateFiles) == 0 {
 		stateFiles = append(stateFiles, "")
 	}
@@ -224,6 +745,7 @@ func (ad *ActionDispatcher) DoV2(filename string, stateFiles []string, actions [
 	if err != nil {
 		return nil, errors.Wrapf(err, "cannot open '%s'", filename)
 	}
+	defer fp.Close()
 	data := bytes.Buffer{}
 	w := bufio.NewWriter(&data)
 	if _, err := io.CopyN(w, fp, 512); err != nil {
@@ -237,54 +759,55 @@ func (ad *ActionDispatcher) DoV2(filename string, stateFiles []string, actions [
 		Mimetypes: []string{contentType},
 		Pronom:    "",
 		Pronoms:   []string{},
+		Checksum:  map[string]string{},
 		Width:     0,
 		Height:    0,
 		Duration:  0,
 		Size:      0,
 		Metadata:  map[string]any{},
 	}
-	for _, actionStr := range actions {
-		var found bool
-		for _, action := range ad.actions {
-			if actionStr == action.GetName() && action.GetCaps()&ACTSTREAM != 0 {
-				found = true
-				if !action.CanHandle(results.Mimetype, filename) {
-					break
-				}
-				// stream to actions
-				result, err := action.DoV2(filename)
-				if err != nil {
-					result = NewResultV2()
-					result.Errors[action.GetName()] = err.Error()
-				}
-				// send result to channel
-				if result != nil {
-					results.Merge(result)
-				}
-				break
-			}
+	ident, other, consumers, err := ad.stages(actions, true)
+	if err != nil {
+		return nil, err
+	}
+	// the characterisation actions get the mimetype of the identification actions
+	stageType := contentType
+	actionCtx := ctx
+	for i, action := range append(ident, other...) {
+		if i == len(ident) && len(ident) > 0 {
+			stageType = ad.stageContentType(results, contentType)
+			actionCtx = withPronoms(ctx, results.Pronoms)
 		}
-		if !found {
-			return nil, errors.Errorf("action '%s' not configured", actionStr)
+		if !canHandle(actionCtx, action, stageType, filename) {
+			ad.metrics.skipped(action.GetName())
+			continue
 		}
+		result, err := ad.doV2Action(actionCtx, action, filename)
+		if err != nil {
+			result = NewResultV2()
+			result.AddError(action.GetName(), err)
+		}
+		if result != nil {
+			results.Merge(result)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrapf(err, "indexing of '%s' aborted", filename)
+		}
+	}
+	if len(other) == 0 && len(ident) > 0 {
+		stageType = ad.stageContentType(results, contentType)
 	}
+	consumers = slices.DeleteFunc(consumers, func(action Action) bool {
+		return !action.CanHandle(stageType, filename)
+	})
+	useResult(results, consumers)
 
-	// sort mimetypes by weight
+	// sort mimetypes by weight, DoV2 keeps its ascending order
 	slices.Sort(results.Mimetypes)
 	results.Mimetypes = slices.Compact(results.Mimetypes)
-	mimeMap := map[string]int{}
-	for _, mimetype := range results.Mimetypes {
-		mimeMap[mimetype] = 50
-		for _, mr := range ad.mimeRelevance {
-			if mr.regexp.MatchString(mimetype) {
-				mimeMap[mimetype] = mr.weight
-				break
-			}
-		}
-	}
-	slices.SortFunc(results.Mimetypes, func(a, b string) int {
-		// higher weight means less in sorting
-		return cmp.Compare(mimeMap[a], mimeMap[b])
+	weights := ad.mimeWeights(results.Mimetypes)
+	slices.SortStableFunc(results.Mimetypes, func(a, b string) int {
+		return cmp.Compare(weights[a], weights[b])
 	})
 	if len(results.Mimetypes) > 0 {
 		results.Mimetype = results.Mimetypes[0]
@@ -298,5 +821,6 @@ func (ad *ActionDispatcher) DoV2(filename string, stateFiles []string, actions [
 		return nil, errors.Wrapf(err, "cannot stat '%s'", filename)
 	}
 	results.Size = uint64(fi.Size())
+	ad.metrics.identified(results)
 	return results, nil
 }
@@ -0,0 +1,306 @@
+package indexer
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stageAction records the content type of CanHandle and the size of the data it gets
+type stageAction struct {
+	name        string
+	caps        ActionCapability
+	handle      string
+	mimetype    string
+	pronom      string
+	contentType string
+	size        int
+	file        string
+}
+
+func (sa *stageAction) Stream(contentType string, reader io.Reader, filename string) (*ResultV2, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	sa.size = len(data)
+	result := NewResultV2()
+	if sa.mimetype != "" {
+		result.Mimetypes = []string{sa.mimetype}
+	}
+	if sa.pronom != "" {
+		result.Pronoms = []string{sa.pronom}
+	}
+	return result, nil
+}
+func (sa *stageAction) DoV2(filename string) (*ResultV2, error) {
+	sa.file = filename
+	fp, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	return sa.Stream("", fp, filename)
+}
+func (sa *stageAction) CanHandle(contentType string, filename string) bool {
+	sa.contentType = contentType
+	return sa.handle == "" || sa.handle == contentType
+}
+func (sa *stageAction) GetName() string           { return sa.name }
+func (sa *stageAction) GetCaps() ActionCapability { return sa.caps }
+func (sa *stageAction) GetWeight() uint           { return 100 }
+
+func TestActionDispatcherStages(t *testing.T) {
+	data := strings.Repeat("plain text ", 1000)
+	filename := filepath.Join(t.TempDir(), "test.bin")
+	assert.NoError(t, os.WriteFile(filename, []byte(data), 0644))
+
+	for _, stream := range []bool{true, false} {
+		ad := NewActionDispatcher(nil)
+		ad.SetTempDir(t.TempDir())
+		ident := &stageAction{name: "ident", caps: ACTSTREAM | ACTIDENT, mimetype: "video/x-test"}
+		other := &stageAction{name: "other", caps: ACTSTREAM, handle: "video/x-test"}
+		ad.RegisterAction(ident)
+		ad.RegisterAction(other)
+
+		var result *ResultV2
+		var err error
+		if stream {
+			result, err = ad.Stream(strings.NewReader(data), []string{"test.bin"}, []string{"other", "ident"})
+		} else {
+			result, err = ad.DoV2(filename, nil, []string{"other", "ident"})
+		}
+		if !assert.NoError(t, err) {
+			continue
+		}
+		assert.Equal(t, len(data), ident.size)
+		assert.Equal(t, "video/x-test", other.contentType)
+		assert.Equal(t, len(data), other.size)
+		assert.Equal(t, uint64(len(data)), result.Size)
+	}
+}
+
+func TestActionDispatcherStagesMaxSize(t *testing.T) {
+	data := strings.Repeat("plain text ", 1000)
+	tempDir := t.TempDir()
+	ad := NewActionDispatcher(nil)
+	ad.SetTempDir(tempDir)
+	ad.SetLocalCache(false, 100)
+	ident := &stageAction{name: "ident", caps: ACTSTREAM | ACTIDENT, mimetype: "video/x-test"}
+	other := &stageAction{name: "other", caps: ACTSTREAM}
+	ad.RegisterAction(ident)
+	ad.RegisterAction(other)
+
+	// too large to spool for the stages, both actions get the stream at once
+	result, err := ad.Stream(strings.NewReader(data), []string{"test.bin"}, []string{"other", "ident"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "text/plain", other.contentType)
+	assert.Equal(t, len(data), ident.size)
+	assert.Equal(t, len(data), other.size)
+	assert.Equal(t, uint64(len(data)), result.Size)
+	entries, err := os.ReadDir(tempDir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestActionDispatcherMimetypeOrder(t *testing.T) {
+	data := strings.Repeat("plain text ", 100)
+	filename := filepath.Join(t.TempDir(), "test.bin")
+	assert.NoError(t, os.WriteFile(filename, []byte(data), 0644))
+	ad := NewActionDispatcher(map[int]MimeWeightString{
+		1: {Regexp: "^video/", Weight: 200},
+		2: {Regexp: "^text/x-", Weight: 10},
+	})
+	ad.RegisterAction(&stageAction{name: "video", caps: ACTSTREAM | ACTIDENT, mimetype: "video/x-test"})
+	ad.RegisterAction(&stageAction{name: "text", caps: ACTSTREAM | ACTIDENT, mimetype: "text/x-test"})
+
+	// Stream sorts the most relevant mimetype first, DoV2 keeps its ascending order of the weights
+	result, err := ad.Stream(strings.NewReader(data), []string{"test.bin"}, []string{"text", "video"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "video/x-test", result.Mimetype)
+	}
+	result, err = ad.DoV2(filename, nil, []string{"text", "video"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "text/x-test", result.Mimetype)
+		assert.Equal(t, "video/x-test", result.Mimetypes[len(result.Mimetypes)-1])
+	}
+}
+
+func TestActionDispatcherCacheSpool(t *testing.T) {
+	data := strings.Repeat("plain text ", 1000)
+	tempDir := t.TempDir()
+	cache, err := NewResultCacheDir(t.TempDir())
+	if !assert.NoError(t, err) {
+		return
+	}
+	ad := NewActionDispatcher(nil)
+	ad.SetCache(cache, "test", tempDir)
+	ad.SetLocalCache(true, 0)
+	ident := &stageAction{name: "ident", caps: ACTSTREAM | ACTIDENT, mimetype: "video/x-test"}
+	file := &stageAction{name: "file", caps: ACTFILEFULL | ACTSTREAM}
+	ad.RegisterAction(ident)
+	ad.RegisterAction(file)
+
+	// the staged actions and the local cache use the spool file of the result cache
+	result, err := ad.Stream(strings.NewReader(data), []string{"test.bin"}, []string{"ident", "file"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, strings.HasPrefix(filepath.Base(file.file), "cache-"))
+	assert.Equal(t, ".bin", filepath.Ext(file.file))
+	assert.Equal(t, len(data), ident.size)
+	assert.Equal(t, len(data), file.size)
+	assert.Equal(t, uint64(len(data)), result.Size)
+	entries, err := os.ReadDir(tempDir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestActionDispatcherFileOnly(t *testing.T) {
+	data := strings.Repeat("plain text ", 100)
+	filename := filepath.Join(t.TempDir(), "test.bin")
+	assert.NoError(t, os.WriteFile(filename, []byte(data), 0644))
+	ad := NewActionDispatcher(nil)
+	file := &stageAction{name: "file", caps: ACTFILE}
+	ad.RegisterAction(file)
+
+	_, err := ad.Stream(strings.NewReader(data), []string{"test.bin"}, []string{"file"})
+	assert.ErrorContains(t, err, "action 'file' needs a file or URL")
+	_, err = ad.Stream(strings.NewReader(data), []string{"test.bin"}, []string{"unknown"})
+	assert.ErrorContains(t, err, "action 'unknown' not configured")
+	_, err = ad.DoV2(filename, nil, []string{"file"})
+	assert.NoError(t, err)
+	assert.Equal(t, filename, file.file)
+}
+
+func TestActionDispatcherLocalCache(t *testing.T) {
+	data := strings.Repeat("plain text ", 1000)
+	tests := []struct {
+		name       string
+		localCache bool
+		maxSize    int64
+		wantFile   bool
+	}{
+		{name: "disabled", localCache: false},
+		{name: "enabled", localCache: true, wantFile: true},
+		{name: "too large", localCache: true, maxSize: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			ad := NewActionDispatcher(nil)
+			ad.SetTempDir(tempDir)
+			ad.SetLocalCache(tt.localCache, tt.maxSize)
+			file := &stageAction{name: "file", caps: ACTFILEFULL | ACTSTREAM}
+			stream := &stageAction{name: "stream", caps: ACTSTREAM}
+			ad.RegisterAction(file)
+			ad.RegisterAction(stream)
+
+			result, err := ad.Stream(strings.NewReader(data), []string{"test.bin"}, []string{"file", "stream"})
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantFile, file.file != "")
+			if tt.wantFile {
+				assert.Equal(t, ".bin", filepath.Ext(file.file))
+			}
+			assert.Equal(t, len(data), file.size)
+			assert.Equal(t, len(data), stream.size)
+			assert.Equal(t, uint64(len(data)), result.Size)
+			entries, err := os.ReadDir(tempDir)
+			assert.NoError(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+}
+
+func TestActionDispatcherHead(t *testing.T) {
+	data := strings.Repeat("0123456789", 100)
+	tests := []struct {
+		name        string
+		reader      io.Reader
+		size        int64
+		wantRead    int
+		wantSize    uint64
+		wantPartial bool
+	}{
+		{name: "short stream", reader: io.MultiReader(strings.NewReader(data[:50])), size: -1, wantRead: 50, wantSize: 50},
+		{name: "long stream", reader: io.MultiReader(strings.NewReader(data)), size: -1, wantRead: 100, wantSize: 0, wantPartial: true},
+		{name: "file with tail", reader: strings.NewReader(data), size: int64(len(data)), wantRead: 120, wantSize: uint64(len(data)), wantPartial: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ad := NewActionDispatcher(nil)
+			ad.SetHead(100, 20, 0)
+			head := &stageAction{name: "head", caps: ACTFILEHEAD | ACTSTREAM}
+			full := &stageAction{name: "full", caps: ACTFILEFULL | ACTSTREAM}
+			ad.RegisterAction(head)
+			ad.RegisterAction(full)
+
+			result, err := ad.HeadContext(context.Background(), tt.reader, tt.size, []string{"test.bin"}, nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantRead, head.size)
+			assert.Equal(t, 0, full.size)
+			assert.Equal(t, tt.wantSize, result.Size)
+			assert.Equal(t, tt.wantPartial, result.Partial)
+		})
+	}
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (rf readerFunc) Read(p []byte) (int, error) { return rf(p) }
+
+// slowFileAction keeps reading its file for a while after ctx is done
+type slowFileAction struct {
+	stageAction
+	started  chan struct{}
+	finished atomic.Bool
+}
+
+func (sa *slowFileAction) StreamContext(ctx context.Context, contentType string, reader io.Reader, filename string) (*ResultV2, error) {
+	return sa.Stream(contentType, reader, filename)
+}
+func (sa *slowFileAction) DoV2Context(ctx context.Context, filename string) (*ResultV2, error) {
+	close(sa.started)
+	<-ctx.Done()
+	time.Sleep(50 * time.Millisecond)
+	_, err := os.Stat(filename)
+	sa.finished.Store(err == nil)
+	return nil, ctx.Err()
+}
+
+func TestActionDispatcherStreamStageCancel(t *testing.T) {
+	data := strings.Repeat("plain text ", 1000)
+	filename := filepath.Join(t.TempDir(), "test.bin")
+	assert.NoError(t, os.WriteFile(filename, []byte(data), 0644))
+
+	ad := NewActionDispatcher(nil)
+	slow := &slowFileAction{stageAction: stageAction{name: "slow", caps: ACTFILE}, started: make(chan struct{})}
+	ad.RegisterAction(slow)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-slow.started
+		cancel()
+	}()
+	// the stream ends after the cancellation
+	reader := io.MultiReader(readerFunc(func(p []byte) (int, error) {
+		<-ctx.Done()
+		return 0, io.EOF
+	}), strings.NewReader(data))
+	_, _, err := ad.streamStage(ctx, reader, "", []string{"test.bin"}, []Action{slow}, filename)
+	assert.ErrorIs(t, err, context.Canceled)
+	// the action has finished with the local file before streamStage returns
+	assert.True(t, slow.finished.Load())
+}
@@ -19,9 +19,20 @@
 package indexer
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"emperror.dev/errors"
 )
@@ -29,20 +40,32 @@ import (
 type ExternalActionCalltype uint
 
 const (
-	EACTURL      ExternalActionCalltype = 1 << iota // url with placehoder for full path
-	EACTJSONPOST                                    // send json struct via post
+	EACTURL        ExternalActionCalltype = 1 << iota // url with placehoder for full path
+	EACTJSONPOST                                      // send json struct via post
+	EACTSTREAMPOST                                    // send data as body via post, url with optional placeholder for filename
 )
 
 var EACTString map[ExternalActionCalltype]string = map[ExternalActionCalltype]string{
-	EACTURL:      "EACTURL",
-	EACTJSONPOST: "EACTJSONPOST",
+	EACTURL:        "EACTURL",
+	EACTJSONPOST:   "EACTJSONPOST",
+	EACTSTREAMPOST: "EACTSTREAMPOST",
 }
 
 var EACTAction map[string]ExternalActionCalltype = map[string]ExternalActionCalltype{
-	"EACTURL":      EACTURL,
-	"EACTJSONPOST": EACTJSONPOST,
+	"EACTURL":        EACTURL,
+	"EACTJSONPOST":   EACTJSONPOST,
+	"EACTSTREAMPOST": EACTSTREAMPOST,
 }
 
+// placeholders within the address
+const (
+	EACTPlaceholderPath     = "[[PATH]]"
+	EACTPlaceholderFilename = "[[FILENAME]]"
+)
+
+// result fields which can be filled from the json response of an external action or command
+var resultMappingFields = []string{"mimetype", "mimetypes", "pronom", "pronoms", "width", "height", "duration", "errors"}
+
 // for toml decoding
 func (a *ExternalActionCalltype) UnmarshalText(text []byte) error {
 	var ok bool
@@ -53,16 +76,38 @@ func (a *ExternalActionCalltype) UnmarshalText(text []byte) error {
 	return nil
 }
 
-func NewActionExternal(name, address string, capability ActionCapability, callType ExternalActionCalltype, mimetype string, ad *ActionDispatcher) Action {
+// NewActionExternal creates an action calling an external http service.
+// mapping assigns result fields (mimetype, mimetypes, pronom, pronoms, width, height, duration, errors)
+// to dot separated paths within the json response. The whole response is stored as metadata.
+func NewActionExternal(name, address string, capability ActionCapability, callType ExternalActionCalltype, mimetype string, mapping map[string]string, timeout time.Duration, ad *ActionDispatcher) (Action, error) {
+	if err := checkMapping(mapping); err != nil {
+		return nil, errors.Wrapf(err, "invalid mapping for external action %s", name)
+	}
+	var mimeRegexp *regexp.Regexp
+	if mimetype != "" {
+		var err error
+		if mimeRegexp, err = regexp.Compile(mimetype); err != nil {
+			return nil, errors.Wrapf(err, "invalid mimetype regexp '%s' for external action %s", mimetype, name)
+		}
+	}
+	if callType == EACTSTREAMPOST {
+		capability |= ACTSTREAM
+	}
+	if timeout == 0 {
+		timeout = 60 * time.Second
+	}
 	ae := &ActionExternal{
 		name:       name,
 		url:        address,
 		capability: capability,
 		callType:   callType,
-		mimetype:   regexp.MustCompile(mimetype),
+		mimetype:   mimeRegexp,
+		mapping:    mapping,
+		timeout:    timeout,
+		client:     &http.Client{Timeout: timeout},
 	}
 	ad.RegisterAction(ae)
-	return ae
+	return ae, nil
 }
 
 type ActionExternal struct {
@@ -71,19 +116,226 @@ type ActionExternal struct {
 	capability ActionCapability
 	callType   ExternalActionCalltype
 	mimetype   *regexp.Regexp
+	mapping    map[string]string
+	timeout    time.Duration
+	client     *http.Client
+}
+
+func (as *ActionExternal) Stream(contentType string, reader io.Reader, filename string) (*ResultV2, error) {
+	return as.StreamContext(context.Background(), contentType, reader, filename)
 }
 
 func (as *ActionExternal) DoV2(filename string) (*ResultV2, error) {
-	//TODO implement me
-	panic("implement me")
+	return as.DoV2Context(context.Background(), filename)
+}
+
+func (as *ActionExternal) DoV2Context(ctx context.Context, filename string) (*ResultV2, error) {
+	switch as.callType {
+	case EACTURL:
+		address := replacePlaceholder(as.url, EACTPlaceholderPath, filepath.ToSlash(filename))
+		address = replacePlaceholder(address, EACTPlaceholderFilename, filepath.Base(filename))
+		return as.call(ctx, http.MethodGet, address, nil, "")
+	case EACTSTREAMPOST:
+		reader, err := os.Open(filename)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot open file '%s'", filename)
+		}
+		defer reader.Close()
+		return as.StreamContext(ctx, "", reader, filename)
+	default:
+		return nil, errors.Errorf("calltype %s of external action %s not supported", EACTString[as.callType], as.name)
+	}
 }
 
 func (as *ActionExternal) CanHandle(contentType string, filename string) bool {
+	if as.mimetype != nil && contentType != "" {
+		return as.mimetype.MatchString(contentType)
+	}
 	return true
 }
 
-func (as *ActionExternal) Stream(contentType string, reader io.Reader, filename string) (*ResultV2, error) {
-	return nil, errors.New("external actions does not support streaming")
+func (as *ActionExternal) StreamContext(ctx context.Context, contentType string, reader io.Reader, filename string) (*ResultV2, error) {
+	if as.callType != EACTSTREAMPOST {
+		return nil, errors.Errorf("calltype %s of external action %s does not support streaming", EACTString[as.callType], as.name)
+	}
+	address := replacePlaceholder(as.url, EACTPlaceholderFilename, filepath.Base(filename))
+	return as.call(ctx, http.MethodPost, address, reader, contentType)
+}
+
+// replacePlaceholder replaces placeholder with value. Within the path of address, every segment of value is path escaped,
+// within the query, value is query escaped.
+func replacePlaceholder(address, placeholder, value string) string {
+	path, query, hasQuery := strings.Cut(address, "?")
+	segments := strings.Split(value, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	path = strings.ReplaceAll(path, placeholder, strings.Join(segments, "/"))
+	if !hasQuery {
+		return path
+	}
+	return path + "?" + strings.ReplaceAll(query, placeholder, url.QueryEscape(value))
+}
+
+// maxExternalResponseSize limits the json response of external services
+const maxExternalResponseSize = 16 << 20
+
+func (as *ActionExternal) call(ctx context.Context, method, address string, body io.Reader, contentType string) (*ResultV2, error) {
+	ctx, cancel := context.WithTimeout(ctx, as.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, address, body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot create request - %v", address)
+	}
+	injectTrace(ctx, req)
+	req.Header.Add("Accept", "application/json")
+	if body != nil {
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		req.Header.Add("Content-Type", contentType)
+	}
+	resp, err := as.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error in request - %v", address)
+	}
+	defer resp.Body.Close()
+	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, maxExternalResponseSize+1))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading body - %v", address)
+	}
+	if len(bodyBytes) > maxExternalResponseSize {
+		return nil, errors.Errorf("response of %v larger than %d bytes", address, maxExternalResponseSize)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.WithMessagef(httpStatusError(resp.StatusCode), "status not ok - %v -> %v: %s", address, resp.Status, string(bodyBytes))
+	}
+	var data any
+	if err := json.Unmarshal(bodyBytes, &data); err != nil {
+		return nil, errors.Wrapf(err, "error decoding json - %v", string(bodyBytes))
+	}
+	return mapResult(as.GetName(), as.mapping, data), nil
+}
+
+func checkMapping(mapping map[string]string) error {
+	for field := range mapping {
+		if !slices.Contains(resultMappingFields, field) {
+			return errors.Errorf("invalid mapping field '%s'", field)
+		}
+	}
+	return nil
+}
+
+// jsonPath returns the value of a dot separated path, numbers are used as array index
+func jsonPath(data any, path string) (any, bool) {
+	if path == "" {
+		return data, true
+	}
+	for _, part := range strings.Split(path, ".") {
+		switch d := data.(type) {
+		case map[string]any:
+			var ok bool
+			if data, ok = d[part]; !ok {
+				return nil, false
+			}
+		case []any:
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= len(d) {
+				return nil, false
+			}
+			data = d[idx]
+		default:
+			return nil, false
+		}
+	}
+	return data, true
+}
+
+func jsonStrings(val any) []string {
+	switch v := val.(type) {
+	case string:
+		if v == "" {
+			return []string{}
+		}
+		return []string{v}
+	case []any:
+		var result = []string{}
+		for _, e := range v {
+			result = append(result, jsonStrings(e)...)
+		}
+		return result
+	case map[string]any:
+		var result = []string{}
+		for key, e := range v {
+			for _, str := range jsonStrings(e) {
+				result = append(result, fmt.Sprintf("%s: %s", key, str))
+			}
+		}
+		return result
+	case nil:
+		return []string{}
+	default:
+		return []string{fmt.Sprintf("%v", v)}
+	}
+}
+
+func jsonUint(val any) (uint, bool) {
+	switch v := val.(type) {
+	case float64:
+		if v < 0 {
+			return 0, false
+		}
+		return uint(math.Floor(v)), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			return 0, false
+		}
+		return uint(math.Floor(f)), true
+	default:
+		return 0, false
+	}
+}
+
+// mapResult stores data as metadata of name and fills the result fields given in mapping
+func mapResult(name string, mapping map[string]string, data any) *ResultV2 {
+	var result = NewResultV2()
+	result.Metadata[name] = data
+	for field, path := range mapping {
+		val, ok := jsonPath(data, path)
+		if !ok {
+			continue
+		}
+		switch field {
+		case "mimetype":
+			if strs := jsonStrings(val); len(strs) > 0 {
+				result.Mimetype = strs[0]
+				result.Mimetypes = append(result.Mimetypes, strs[0])
+			}
+		case "mimetypes":
+			result.Mimetypes = append(result.Mimetypes, jsonStrings(val)...)
+		case "pronom":
+			if strs := jsonStrings(val); len(strs) > 0 {
+				result.Pronom = strs[0]
+				result.Pronoms = append(result.Pronoms, strs[0])
+			}
+		case "pronoms":
+			result.Pronoms = append(result.Pronoms, jsonStrings(val)...)
+		case "width":
+			result.Width, _ = jsonUint(val)
+		case "height":
+			result.Height, _ = jsonUint(val)
+		case "duration":
+			result.Duration, _ = jsonUint(val)
+		case "errors":
+			if strs := jsonStrings(val); len(strs) > 0 {
+				message := strings.Join(strs, "; ")
+				result.Errors[name] = message
+				result.ActionErrors = append(result.ActionErrors, NewActionError(IndexerOutputParse, name, message))
+			}
+		}
+	}
+	return result
 }
 
 func (as *ActionExternal) GetWeight() uint {
@@ -99,5 +351,6 @@ func (as *ActionExternal) GetName() string {
 }
 
 var (
-	_ Action = (*ActionExternal)(nil)
+	_ Action        = (*ActionExternal)(nil)
+	_ ContextAction = (*ActionExternal)(nil)
 )
@@ -0,0 +1,142 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActionExternal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var size int
+		switch r.Method {
+		case http.MethodGet:
+			path := r.URL.Query().Get("path")
+			if strings.HasPrefix(r.URL.Path, "/file/") {
+				path = strings.TrimPrefix(r.URL.Path, "/file")
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			size = len(data)
+		case http.MethodPost:
+			data, _ := io.ReadAll(r.Body)
+			size = len(data)
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"format": map[string]any{"mimetype": "image/x-test", "pronoms": []string{"x-fmt/1", "x-fmt/2"}},
+			"image":  map[string]any{"width": size, "height": "12.7"},
+			"errors": []string{"broken header"},
+		})
+	}))
+	defer server.Close()
+
+	// the placeholder in the path needs an escaped space, but the slashes are kept
+	folder := filepath.Join(t.TempDir(), "with space")
+	assert.NoError(t, os.Mkdir(folder, 0755))
+	filename := filepath.Join(folder, "test.img")
+	assert.NoError(t, os.WriteFile(filename, []byte("0123456789"), 0644))
+	mapping := map[string]string{
+		"mimetype": "format.mimetype",
+		"pronoms":  "format.pronoms",
+		"width":    "image.width",
+		"height":   "image.height",
+		"errors":   "errors",
+	}
+
+	tests := []struct {
+		name     string
+		address  string
+		callType ExternalActionCalltype
+		stream   bool
+	}{
+		{name: "url", address: server.URL + "/test?path=[[PATH]]", callType: EACTURL},
+		{name: "url path", address: server.URL + "/file[[PATH]]", callType: EACTURL},
+		{name: "post", address: server.URL + "/test?filename=[[FILENAME]]", callType: EACTSTREAMPOST},
+		{name: "post stream", address: server.URL + "/test?filename=[[FILENAME]]", callType: EACTSTREAMPOST, stream: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ad := NewActionDispatcher(nil)
+			action, err := NewActionExternal("ext", tt.address, ACTFILE, tt.callType, "", mapping, 0, ad)
+			assert.NoError(t, err)
+			var result *ResultV2
+			if tt.stream {
+				result, err = action.Stream("", strings.NewReader("0123456789"), "test.img")
+			} else {
+				result, err = action.DoV2(filename)
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "image/x-test", result.Mimetype)
+			assert.Equal(t, []string{"x-fmt/1", "x-fmt/2"}, result.Pronoms)
+			assert.Equal(t, uint(10), result.Width)
+			assert.Equal(t, uint(12), result.Height)
+			assert.Equal(t, "broken header", result.Errors["ext"])
+			if assert.Len(t, result.ActionErrors, 1) {
+				assert.Equal(t, errorID(IndexerOutputParse), result.ActionErrors[0].ID)
+			}
+			assert.NotNil(t, result.Metadata["ext"])
+		})
+	}
+
+	_, err := NewActionExternal("ext", server.URL, ACTFILE, EACTURL, "", map[string]string{"size": "size"}, 0, NewActionDispatcher(nil))
+	assert.Error(t, err)
+}
+
+func TestReplacePlaceholder(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{address: "http://localhost/exif/[[PATH]]", want: "http://localhost/exif//data/a%20b/c%3F.tif"},
+		{address: "http://localhost/exif?path=[[PATH]]", want: "http://localhost/exif?path=%2Fdata%2Fa+b%2Fc%3F.tif"},
+		{address: "http://localhost/exif/[[PATH]]?path=[[PATH]]", want: "http://localhost/exif//data/a%20b/c%3F.tif?path=%2Fdata%2Fa+b%2Fc%3F.tif"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, replacePlaceholder(tt.address, EACTPlaceholderPath, "/data/a b/c?.tif"))
+	}
+}
+
+func TestActionExternalLimits(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			<-done
+		case "/large":
+			w.Write([]byte(`"`))
+			w.Write(bytes.Repeat([]byte("x"), maxExternalResponseSize))
+			w.Write([]byte(`"`))
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	ad := NewActionDispatcher(nil)
+	slow, err := NewActionExternal("slow", server.URL+"/slow", ACTFILE, EACTSTREAMPOST, "", nil, 50*time.Millisecond, ad)
+	assert.NoError(t, err)
+	_, err = slow.Stream("", strings.NewReader("0123456789"), "test.img")
+	assert.Error(t, err)
+
+	large, err := NewActionExternal("large", server.URL+"/large", ACTFILE, EACTSTREAMPOST, "", nil, 0, ad)
+	assert.NoError(t, err)
+	_, err = large.Stream("", strings.NewReader("0123456789"), "test.img")
+	assert.ErrorContains(t, err, "larger than")
+
+	// url actions cannot index a stream
+	_, err = NewActionExternal("url", server.URL+"/test?path=[[PATH]]", ACTFILE, EACTURL, "", nil, 0, ad)
+	assert.NoError(t, err)
+	_, err = ad.Stream(strings.NewReader("0123456789"), []string{"test.img"}, []string{"url"})
+	assert.ErrorContains(t, err, "action 'url' needs a file or URL")
+}
@@ -97,7 +97,8 @@ func (as *ActionFFProbe) CanHandle(contentType string, filename string) bool {
 }
 
 func NewActionFFProbe(name string, ffprobe string, wsl bool, timeout time.Duration, online bool, mime []FFMPEGMime, ad *ActionDispatcher) Action {
-	var caps ActionCapability = ACTFILEHEAD | ACTSTREAM
+	// mp4 files with the moov atom at the end need the complete file
+	var caps ActionCapability = ACTFILEFULL | ACTSTREAM
 	if online {
 		caps |= ACTALLPROTO
 	}
@@ -108,7 +109,7 @@ func NewActionFFProbe(name string, ffprobe string, wsl bool, timeout time.Durati
 		timeout = time.Second * 15
 	}
 	if caps == 0 {
-		caps = ACTFILEHEAD | ACTSTREAM
+		caps = ACTFILEFULL | ACTSTREAM
 	}
 
 	af := &ActionFFProbe{name: name, ffprobe: ffprobe, wsl: wsl, timeout: timeout, caps: caps, mime: mime}
@@ -129,6 +130,14 @@ func (as *ActionFFProbe) GetName() string {
 }
 
 func (as *ActionFFProbe) Stream(contentType string, reader io.Reader, filename string) (*ResultV2, error) {
+	return as.StreamContext(context.Background(), contentType, reader, filename)
+}
+
+func (as *ActionFFProbe) DoV2(filename string) (*ResultV2, error) {
+	return as.DoV2Context(context.Background(), filename)
+}
+
+func (as *ActionFFProbe) StreamContext(ctx context.Context, contentType string, reader io.Reader, filename string) (*ResultV2, error) {
 	if !as.CanHandle(contentType, filename) {
 		return nil, nil
 	}
@@ -144,15 +153,16 @@ func (as *ActionFFProbe) Stream(contentType string, reader io.Reader, filename s
 
 	var out bytes.Buffer
 	out.Grow(1024 * 1024) // 1MB size
-	ctx, cancel := context.WithTimeout(context.Background(), as.timeout)
+	ctx, cancel := context.WithTimeout(ctx, as.timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, cmdfile, cmdparam...)
+	cmd.WaitDelay = commandWaitDelay
 	cmd.Stdin = reader
 	cmd.Stdout = &out
 
-	if err := cmd.Run(); err != nil {
-		return nil, errors.Wrapf(err, "error executing (%s %s) for file '%s': %v", cmdfile, cmdparam, filename, out.String())
+	if err := runCommand(ctx, cmd); err != nil {
+		return nil, errors.Wrapf(commandError(ctx, err), "error executing (%s %s) for file '%s': %v", cmdfile, cmdparam, filename, out.String())
 	}
 
 	var metadata ffmpeg_models.Metadata
@@ -198,7 +208,7 @@ func (as *ActionFFProbe) Stream(contentType string, reader io.Reader, filename s
 	return result, nil
 }
 
-func (as *ActionFFProbe) DoV2(filename string) (*ResultV2, error) {
+func (as *ActionFFProbe) DoV2Context(ctx context.Context, filename string) (*ResultV2, error) {
 	cmdparam := []string{"-i", filename, "-v", "quiet", "-print_format", "json", "-show_format", "-show_streams", "-show_error"}
 	cmdfile := as.ffprobe
 	if as.wsl {
@@ -208,14 +218,15 @@ func (as *ActionFFProbe) DoV2(filename string) (*ResultV2, error) {
 
 	var out bytes.Buffer
 	out.Grow(1024 * 1024) // 1MB size
-	ctx, cancel := context.WithTimeout(context.Background(), as.timeout)
+	ctx, cancel := context.WithTimeout(ctx, as.timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, cmdfile, cmdparam...)
+	cmd.WaitDelay = commandWaitDelay
 	cmd.Stdout = &out
 
-	if err := cmd.Run(); err != nil {
-		return nil, errors.Wrapf(err, "error executing (%s %s) for file '%s': %v", cmdfile, cmdparam, filename, out.String())
+	if err := runCommand(ctx, cmd); err != nil {
+		return nil, errors.Wrapf(commandError(ctx, err), "error executing (%s %s) for file '%s': %v", cmdfile, cmdparam, filename, out.String())
 	}
 
 	var metadata ffmpeg_models.Metadata
@@ -255,5 +266,6 @@ func (as *ActionFFProbe) DoV2(filename string) (*ResultV2, error) {
 }
 
 var (
-	_ Action = &ActionFFProbe{}
+	_ Action        = &ActionFFProbe{}
+	_ ContextAction = &ActionFFProbe{}
 )
@@ -104,7 +104,8 @@ func (ai *ActionIdentifyV2) GetWeight() uint {
 }
 
 func (ai *ActionIdentifyV2) GetCaps() ActionCapability {
-	return ACTFILEHEAD | ACTSTREAM
+	// tiff, psd and pdf need the complete file
+	return ACTFILEFULL | ACTSTREAM
 }
 
 func (ai *ActionIdentifyV2) GetName() string {
@@ -112,6 +113,14 @@ func (ai *ActionIdentifyV2) GetName() string {
 }
 
 func (ai *ActionIdentifyV2) Stream(contentType string, reader io.Reader, filename string) (*ResultV2, error) {
+	return ai.StreamContext(context.Background(), contentType, reader, filename)
+}
+
+func (ai *ActionIdentifyV2) DoV2(filename string) (*ResultV2, error) {
+	return ai.DoV2Context(context.Background(), filename)
+}
+
+func (ai *ActionIdentifyV2) StreamContext(ctx context.Context, contentType string, reader io.Reader, filename string) (*ResultV2, error) {
 	if slices.Contains([]string{"audio", "video", "pdf"}, contentType) {
 		return nil, nil
 	}
@@ -134,15 +143,16 @@ func (ai *ActionIdentifyV2) Stream(contentType string, reader io.Reader, filenam
 
 	var out bytes.Buffer
 	out.Grow(1024 * 1024) // 1MB size
-	ctx, cancel := context.WithTimeout(context.Background(), ai.timeout)
+	ctx, cancel := context.WithTimeout(ctx, ai.timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, cmdParts[0], cmdParts[1:]...)
+	cmd.WaitDelay = commandWaitDelay
 	cmd.Stdin = reader
 	cmd.Stdout = &out
 
-	if err := cmd.Run(); err != nil {
-		return nil, errors.Wrapf(err, "error executing (%s) for file '%s': %v", strings.Join(cmdParts, " "), filename, out.String())
+	if err := runCommand(ctx, cmd); err != nil {
+		return nil, errors.Wrapf(commandError(ctx, err), "error executing (%s) for file '%s': %v", strings.Join(cmdParts, " "), filename, out.String())
 	}
 
 	var meta = []*MagickResult{}
@@ -197,7 +207,7 @@ func (ai *ActionIdentifyV2) Stream(contentType string, reader io.Reader, filenam
 	return result, nil
 }
 
-func (ai *ActionIdentifyV2) DoV2(filename string) (*ResultV2, error) {
+func (ai *ActionIdentifyV2) DoV2Context(ctx context.Context, filename string) (*ResultV2, error) {
 	infile := filename
 	for re, t := range ai.extensionMap {
 		if re.MatchString(filename) {
@@ -214,14 +224,15 @@ func (ai *ActionIdentifyV2) DoV2(filename string) (*ResultV2, error) {
 
 	var out bytes.Buffer
 	out.Grow(1024 * 1024) // 1MB size
-	ctx, cancel := context.WithTimeout(context.Background(), ai.timeout)
+	ctx, cancel := context.WithTimeout(ctx, ai.timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, cmdfile, cmdparam...)
+	cmd.WaitDelay = commandWaitDelay
 	cmd.Stdout = &out
 
-	if err := cmd.Run(); err != nil {
-		return nil, errors.Wrapf(err, "error executing (%s %s) for file '%s': %v", cmdfile, cmdparam, filename, out.String())
+	if err := runCommand(ctx, cmd); err != nil {
+		return nil, errors.Wrapf(commandError(ctx, err), "error executing (%s %s) for file '%s': %v", cmdfile, cmdparam, filename, out.String())
 	}
 
 	var meta = []*MagickResult{}
@@ -272,5 +283,6 @@ func (ai *ActionIdentifyV2) DoV2(filename string) (*ResultV2, error) {
 }
 
 var (
-	_ Action = (*ActionIdentifyV2)(nil)
+	_ Action        = (*ActionIdentifyV2)(nil)
+	_ ContextAction = (*ActionIdentifyV2)(nil)
 )
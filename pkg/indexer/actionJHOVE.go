@@ -0,0 +1,300 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+)
+
+// pdfPronoms are the pronoms of PDF, PDF/A, PDF/X and PDF/E
+var pdfPronoms = []string{"fmt/14", "fmt/15", "fmt/16", "fmt/17", "fmt/18", "fmt/19", "fmt/20", "fmt/95", "fmt/144",
+	"fmt/145", "fmt/146", "fmt/147", "fmt/148", "fmt/157", "fmt/276", "fmt/354", "fmt/476", "fmt/477", "fmt/478"}
+
+// jhoveModulePronoms are the pronoms of the formats of the JHOVE modules
+var jhoveModulePronoms = map[string][]string{
+	"JPEG-hul": {"fmt/41", "fmt/42", "fmt/43", "fmt/44", "fmt/112", "x-fmt/390", "x-fmt/391", "x-fmt/398"},
+	"TIFF-hul": {"fmt/7", "fmt/8", "fmt/9", "fmt/10", "fmt/152", "fmt/153", "fmt/154", "fmt/155", "fmt/156",
+		"fmt/353", "x-fmt/387", "x-fmt/388", "x-fmt/399"},
+	"PDF-hul": pdfPronoms,
+	"WAVE-hul": {"fmt/1", "fmt/2", "fmt/6", "fmt/141", "fmt/142", "fmt/143", "fmt/527", "fmt/703", "fmt/704",
+		"fmt/705", "fmt/706", "fmt/707", "fmt/708", "fmt/709", "fmt/710", "fmt/711"},
+	"AIFF-hul":     {"x-fmt/135", "x-fmt/136", "fmt/414"},
+	"GIF-hul":      {"fmt/3", "fmt/4"},
+	"JPEG2000-hul": {"x-fmt/392", "fmt/151"},
+	"XML-hul":      {"fmt/101"},
+	"HTML-hul":     {"fmt/96", "fmt/97", "fmt/98", "fmt/99", "fmt/100", "fmt/102", "fmt/103", "fmt/471"},
+}
+
+// jhoveMimeModules maps the mimetypes to the JHOVE modules, if the pronom is unknown
+var jhoveMimeModules = map[string]string{
+	"image/jpeg":            "JPEG-hul",
+	"image/tiff":            "TIFF-hul",
+	"application/pdf":       "PDF-hul",
+	"audio/wav":             "WAVE-hul",
+	"audio/wave":            "WAVE-hul",
+	"audio/x-wav":           "WAVE-hul",
+	"audio/vnd.wave":        "WAVE-hul",
+	"audio/aiff":            "AIFF-hul",
+	"audio/x-aiff":          "AIFF-hul",
+	"image/gif":             "GIF-hul",
+	"image/jp2":             "JPEG2000-hul",
+	"image/jpx":             "JPEG2000-hul",
+	"application/xml":       "XML-hul",
+	"text/xml":              "XML-hul",
+	"text/html":             "HTML-hul",
+	"application/xhtml+xml": "HTML-hul",
+	"text/plain":            "UTF8-hul",
+}
+
+// jhoveExtModules maps the file extensions to the JHOVE modules, if neither pronom nor mimetype are known
+var jhoveExtModules = map[string]string{
+	".jpg": "JPEG-hul", ".jpeg": "JPEG-hul", ".jpe": "JPEG-hul",
+	".tif": "TIFF-hul", ".tiff": "TIFF-hul",
+	".pdf": "PDF-hul",
+	".wav": "WAVE-hul",
+	".aif": "AIFF-hul", ".aiff": "AIFF-hul", ".aifc": "AIFF-hul",
+	".gif": "GIF-hul",
+	".jp2": "JPEG2000-hul", ".jpx": "JPEG2000-hul",
+	".xml": "XML-hul",
+	".htm": "HTML-hul", ".html": "HTML-hul", ".xhtml": "HTML-hul",
+	".txt": "UTF8-hul",
+}
+
+// JHOVEResult is the validation result of JHOVE
+type JHOVEResult struct {
+	Module     string         `json:"module"`
+	Release    string         `json:"release,omitempty"`
+	Format     string         `json:"format,omitempty"`
+	Version    string         `json:"version,omitempty"`
+	Status     string         `json:"status"`
+	WellFormed bool           `json:"wellformed"`
+	Valid      bool           `json:"valid"`
+	Profiles   []string       `json:"profiles,omitempty"`
+	Messages   []JHOVEMessage `json:"messages,omitempty"`
+}
+
+type JHOVEMessage struct {
+	ID         string `json:"id,omitempty"`
+	Severity   string `json:"severity,omitempty"`
+	Message    string `json:"message"`
+	SubMessage string `json:"subMessage,omitempty"`
+	Offset     *int64 `json:"offset,omitempty"`
+}
+
+// jhoveOutput is the part of the output of the JHOVE json handler, which is used
+type jhoveOutput struct {
+	JHOVE struct {
+		Release string `json:"release"`
+		RepInfo []struct {
+			Format   string         `json:"format"`
+			Version  string         `json:"version"`
+			Status   string         `json:"status"`
+			MimeType string         `json:"mimeType"`
+			Profiles []string       `json:"profiles"`
+			Messages []JHOVEMessage `json:"messages"`
+		} `json:"repInfo"`
+	} `json:"jhove"`
+}
+
+type ActionJHOVE struct {
+	name    string
+	jhove   string
+	config  string
+	wsl     bool
+	timeout time.Duration
+	tempDir string
+	caps    ActionCapability
+}
+
+// NewActionJHOVE creates an action, which validates files with a local JHOVE installation.
+// JHOVE 1.20 or later is needed, older releases have no json output handler.
+// The module is chosen by the identified pronom, the mimetype or the file extension.
+// Streams are spooled to tempDir. config is an optional jhove.conf.
+func NewActionJHOVE(name, jhove, config string, wsl bool, timeout time.Duration, tempDir string, ad *ActionDispatcher) Action {
+	if name == "" {
+		name = NameJHOVE
+	}
+	if timeout == 0 {
+		timeout = time.Second * 60
+	}
+	aj := &ActionJHOVE{
+		name:    name,
+		jhove:   jhove,
+		config:  config,
+		wsl:     wsl,
+		timeout: timeout,
+		tempDir: tempDir,
+		caps:    ACTFILEFULL | ACTSTREAM,
+	}
+	ad.RegisterAction(aj)
+	return aj
+}
+
+func (aj *ActionJHOVE) CanHandle(contentType string, filename string) bool {
+	mimetype, _, _ := strings.Cut(contentType, ";")
+	if _, ok := jhoveMimeModules[strings.TrimSpace(mimetype)]; ok {
+		return true
+	}
+	_, ok := jhoveExtModules[strings.ToLower(filepath.Ext(filename))]
+	return ok
+}
+
+// CanHandlePronoms returns true, if one of the identified pronoms has a JHOVE module
+func (aj *ActionJHOVE) CanHandlePronoms(pronoms []string) bool {
+	return jhovePronomModule(pronoms) != ""
+}
+
+// jhovePronomModule returns the JHOVE module of the first pronom with a module or an empty string
+func jhovePronomModule(pronoms []string) string {
+	for _, pronom := range pronoms {
+		for module, modulePronoms := range jhoveModulePronoms {
+			if slices.Contains(modulePronoms, pronom) {
+				return module
+			}
+		}
+	}
+	return ""
+}
+
+// module returns the JHOVE module for the file or an empty string
+func (aj *ActionJHOVE) module(ctx context.Context, contentType string, filename string) string {
+	if module := jhovePronomModule(stagePronoms(ctx)); module != "" {
+		return module
+	}
+	mimetype, _, _ := strings.Cut(contentType, ";")
+	if module, ok := jhoveMimeModules[strings.TrimSpace(mimetype)]; ok {
+		return module
+	}
+	return jhoveExtModules[strings.ToLower(filepath.Ext(filename))]
+}
+
+func (aj *ActionJHOVE) Stream(contentType string, reader io.Reader, filename string) (*ResultV2, error) {
+	return aj.StreamContext(context.Background(), contentType, reader, filename)
+}
+
+func (aj *ActionJHOVE) DoV2(filename string) (*ResultV2, error) {
+	return aj.DoV2Context(context.Background(), filename)
+}
+
+func (aj *ActionJHOVE) StreamContext(ctx context.Context, contentType string, reader io.Reader, filename string) (*ResultV2, error) {
+	module := aj.module(ctx, contentType, filename)
+	if module == "" {
+		return nil, nil
+	}
+	tmpFile, err := os.CreateTemp(aj.tempDir, "jhove-*"+filepath.Ext(filename))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create temporary file")
+	}
+	tmpName := tmpFile.Name()
+	defer os.Remove(tmpName)
+	if _, err := io.Copy(tmpFile, reader); err != nil {
+		tmpFile.Close()
+		return nil, errors.Wrapf(err, "cannot write data of '%s' to '%s'", filename, tmpName)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return nil, errors.Wrapf(err, "cannot close temporary file '%s'", tmpName)
+	}
+	return aj.validate(ctx, module, tmpName)
+}
+
+func (aj *ActionJHOVE) DoV2Context(ctx context.Context, filename string) (*ResultV2, error) {
+	module := aj.module(ctx, "", filename)
+	if module == "" {
+		return nil, nil
+	}
+	return aj.validate(ctx, module, filename)
+}
+
+func (aj *ActionJHOVE) validate(ctx context.Context, module string, filename string) (*ResultV2, error) {
+	path := filename
+	if aj.wsl {
+		path = pathToWSL(filename)
+	}
+	cmdparam := []string{"-m", module, "-h", "json"}
+	if aj.config != "" {
+		cmdparam = append([]string{"-c", aj.config}, cmdparam...)
+	}
+	cmdparam = append(cmdparam, path)
+	cmdfile := aj.jhove
+	if aj.wsl {
+		cmdparam = append([]string{cmdfile}, cmdparam...)
+		cmdfile = "wsl"
+	}
+
+	var out, errOut bytes.Buffer
+	ctx, cancel := context.WithTimeout(ctx, aj.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, cmdfile, cmdparam...)
+	cmd.WaitDelay = commandWaitDelay
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+
+	if err := runCommand(ctx, cmd); err != nil {
+		return nil, errors.Wrapf(commandError(ctx, err), "error executing (%s %s) for file '%s': %v", cmdfile, cmdparam, filename, errOut.String())
+	}
+
+	var output jhoveOutput
+	if err := json.Unmarshal(out.Bytes(), &output); err != nil {
+		return nil, errors.Wrapf(err, "cannot unmarshal jhove output: %s", out.String())
+	}
+	if len(output.JHOVE.RepInfo) == 0 {
+		return nil, errors.Errorf("no jhove result for file '%s': %s", filename, out.String())
+	}
+	repInfo := output.JHOVE.RepInfo[0]
+	status := strings.ToLower(repInfo.Status)
+	jr := &JHOVEResult{
+		Module:     module,
+		Release:    output.JHOVE.Release,
+		Format:     repInfo.Format,
+		Version:    repInfo.Version,
+		Status:     repInfo.Status,
+		WellFormed: strings.HasPrefix(status, "well-formed"),
+		Profiles:   repInfo.Profiles,
+		Messages:   repInfo.Messages,
+	}
+	jr.Valid = jr.WellFormed && !strings.Contains(status, "not valid")
+
+	result := NewResultV2()
+	result.Metadata[aj.GetName()] = jr
+	return result, nil
+}
+
+func (aj *ActionJHOVE) GetWeight() uint {
+	return 70
+}
+
+func (aj *ActionJHOVE) GetCaps() ActionCapability {
+	return aj.caps
+}
+
+func (aj *ActionJHOVE) GetName() string {
+	return aj.name
+}
+
+var (
+	_ Action        = &ActionJHOVE{}
+	_ ContextAction = &ActionJHOVE{}
+)
@@ -0,0 +1,114 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// jhoveScript prints the output of the JHOVE json handler with the module as format
+const jhoveScript = `#!/bin/sh
+# -m <module> -h json <file>
+if [ "$2" = "PDF-hul" ]; then
+  status="Well-Formed, but not valid"
+  messages='[{"message": "Invalid destination object", "severity": "error", "id": "PDF-HUL-2", "offset": 1234}]'
+else
+  status="Well-Formed and valid"
+  messages='[]'
+fi
+cat <<EOF
+{"jhove": {"name": "Jhove", "release": "1.28.0", "repInfo": [{"uri": "$5", "format": "$2", "version": "1.4", "status": "$status", "messages": $messages}]}}
+EOF
+`
+
+func TestActionJHOVE(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script as jhove")
+	}
+	folder := t.TempDir()
+	jhove := filepath.Join(folder, "jhove")
+	assert.NoError(t, os.WriteFile(jhove, []byte(jhoveScript), 0755))
+
+	tests := []struct {
+		name        string
+		contentType string
+		filename    string
+		pronoms     []string
+		wantModule  string
+		wantValid   bool
+		wantMessage string
+	}{
+		{name: "pronom", filename: "test.bin", pronoms: []string{"fmt/353"}, wantModule: "TIFF-hul", wantValid: true},
+		{name: "mimetype", contentType: "application/pdf", filename: "test.bin", wantModule: "PDF-hul", wantMessage: "PDF-HUL-2"},
+		{name: "extension", filename: "test.WAV", wantModule: "WAVE-hul", wantValid: true},
+		{name: "unknown", contentType: "video/mp4", filename: "test.mp4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ad := NewActionDispatcher(nil)
+			action := NewActionJHOVE("", jhove, "", false, 0, folder, ad).(*ActionJHOVE)
+			ctx := context.Background()
+			if tt.pronoms != nil {
+				ctx = withPronoms(ctx, tt.pronoms)
+			}
+			result, err := action.StreamContext(ctx, tt.contentType, strings.NewReader("0123456789"), tt.filename)
+			if !assert.NoError(t, err) {
+				return
+			}
+			if tt.wantModule == "" {
+				assert.Nil(t, result)
+				return
+			}
+			jr, ok := result.Metadata[NameJHOVE].(*JHOVEResult)
+			if !assert.True(t, ok) {
+				return
+			}
+			assert.Equal(t, tt.wantModule, jr.Module)
+			assert.Equal(t, tt.wantModule, jr.Format)
+			assert.Equal(t, "1.28.0", jr.Release)
+			assert.True(t, jr.WellFormed)
+			assert.Equal(t, tt.wantValid, jr.Valid)
+			if tt.wantMessage != "" && assert.Len(t, jr.Messages, 1) {
+				assert.Equal(t, tt.wantMessage, jr.Messages[0].ID)
+				assert.Equal(t, int64(1234), *jr.Messages[0].Offset)
+			}
+		})
+	}
+	// the spooled files are removed
+	files, _ := filepath.Glob(filepath.Join(folder, "jhove-*"))
+	assert.Empty(t, files)
+}
+
+func TestActionJHOVE_Dispatcher(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script as jhove")
+	}
+	folder := t.TempDir()
+	jhove := filepath.Join(folder, "jhove")
+	assert.NoError(t, os.WriteFile(jhove, []byte(jhoveScript), 0755))
+	data := strings.Repeat("\x00\x01", 1000)
+
+	for _, pronom := range []string{"fmt/353", "fmt/199"} {
+		ad := NewActionDispatcher(nil)
+		ad.RegisterAction(&stageAction{name: "ident", caps: ACTSTREAM | ACTIDENT, pronom: pronom})
+		NewActionJHOVE("", jhove, "", false, 0, folder, ad)
+		// neither the mimetype nor the extension have a module, the identified pronom is used
+		result, err := ad.Stream(strings.NewReader(data), []string{"test.bin"}, []string{"ident", NameJHOVE})
+		if !assert.NoError(t, err) {
+			return
+		}
+		jr, ok := result.Metadata[NameJHOVE].(*JHOVEResult)
+		if pronom == "fmt/199" {
+			assert.False(t, ok)
+			continue
+		}
+		if assert.True(t, ok) {
+			assert.Equal(t, "TIFF-hul", jr.Module)
+		}
+	}
+}
@@ -66,7 +66,7 @@ func (as *ActionJSON) GetWeight() uint {
 }
 
 func (as *ActionJSON) GetCaps() ActionCapability {
-	return ACTFILEHEAD | ACTSTREAM
+	return ACTFILEHEAD | ACTSTREAM | ACTIDENT
 }
 
 func (as *ActionJSON) GetName() string {
@@ -14,23 +14,25 @@
 package indexer
 
 import (
-	"encoding/json"
+	"io"
+	"os"
+	"strings"
 
 	"emperror.dev/errors"
-	badger "github.com/dgraph-io/badger/v4"
-	"github.com/golang/snappy"
+	"github.com/je4/utils/v2/pkg/checksum"
 	"github.com/je4/utils/v2/pkg/zLogger"
-
-	"io"
 )
 
 const NSRL_OS = "OpSystemCode-"
 const NSRL_PROD = "ProductCode-"
-const NSRL_MFG = "MFgCode-"
+const NSRL_MFG = "MfgCode-"
 const NSRL_File = "SHA-1-"
 
-func NewActionNSRL(name string, nsrldb *badger.DB, ad *ActionDispatcher, logger zLogger.ZLogger) Action {
-	an := &ActionNSRL{name: name, nsrldb: nsrldb, caps: ACTFILE, logger: logger}
+// maximum number of file entries returned per checksum
+const nsrlMaxFileEntries = 10
+
+func NewActionNSRL(name string, store NSRLStore, ad *ActionDispatcher, logger zLogger.ZLogger) Action {
+	an := &ActionNSRL{name: name, store: store, caps: ACTFILE | ACTSTREAM, logger: logger}
 	ad.RegisterAction(an)
 	return an
 }
@@ -38,13 +40,27 @@ func NewActionNSRL(name string, nsrldb *badger.DB, ad *ActionDispatcher, logger
 type ActionNSRL struct {
 	name   string
 	caps   ActionCapability
-	nsrldb *badger.DB
+	store  NSRLStore
 	logger zLogger.ZLogger
 }
 
+// ActionNSRLResult is stored in the metadata of the result
+type ActionNSRLResult struct {
+	// Known is true, if the checksum has been found in the NSRL
+	Known bool `json:"known"`
+	// Probable is true, if the checksum is only in the bloom filter and there is no store to confirm it
+	Probable bool             `json:"probable,omitempty"`
+	SHA1     string           `json:"sha1"`
+	Entries  []ActionNSRLMeta `json:"entries,omitempty"`
+}
+
 func (aNSRL *ActionNSRL) DoV2(filename string) (*ResultV2, error) {
-	//TODO implement me
-	panic("implement me")
+	reader, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open file '%s'", filename)
+	}
+	defer reader.Close()
+	return aNSRL.Stream("", reader, filename)
 }
 
 func (aNSRL *ActionNSRL) CanHandle(contentType string, filename string) bool {
@@ -52,111 +68,114 @@ func (aNSRL *ActionNSRL) CanHandle(contentType string, filename string) bool {
 }
 
 func (aNSRL *ActionNSRL) Stream(contentType string, reader io.Reader, filename string) (*ResultV2, error) {
-	return nil, errors.New("nsrl actions does not support streaming")
+	sha1sum, err := checksum.Checksum(reader, checksum.DigestSHA1)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot calculate sha1 of '%s'", filename)
+	}
+	return aNSRL.lookup(sha1sum)
+}
+
+// CanUseResult returns true, if one of the actions calculates the SHA-1 checksum within the stream
+func (aNSRL *ActionNSRL) CanUseResult(actions []Action) bool {
+	for _, action := range actions {
+		if ac, ok := action.(*ActionChecksum); ok && ac.HasDigest(checksum.DigestSHA1) {
+			return true
+		}
+	}
+	return false
+}
+
+// UseResult does the NSRL lookup with the SHA-1 checksum of the merged result
+func (aNSRL *ActionNSRL) UseResult(result *ResultV2) (*ResultV2, error) {
+	sha1sum, ok := result.Checksum[string(checksum.DigestSHA1)]
+	if !ok || sha1sum == "" {
+		return nil, errors.New("no sha1 checksum in result")
+	}
+	return aNSRL.lookup(sha1sum)
+}
+
+func (aNSRL *ActionNSRL) lookup(sha1sum string) (*ResultV2, error) {
+	entries, err := aNSRL.getNSRL(sha1sum)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot query nsrl for %s", sha1sum)
+	}
+	nsrlResult := &ActionNSRLResult{
+		Known:   len(entries) > 0,
+		SHA1:    strings.ToLower(sha1sum),
+		Entries: entries,
+	}
+	if ps, ok := aNSRL.store.(NSRLProbableStore); ok && !nsrlResult.Known {
+		nsrlResult.Probable = ps.Probable(NSRL_File + strings.ToUpper(sha1sum))
+	}
+	var result = NewResultV2()
+	result.Metadata[aNSRL.GetName()] = nsrlResult
+	return result, nil
 }
 
 type ActionNSRLMeta struct {
-	File    map[string]string
-	FileMfG map[string]string
-	OS      map[string]string
-	OSMfg   map[string]string
-	Prod    map[string]string
-	ProdMfg map[string]string
+	File    map[string]string `json:"file,omitempty"`
+	FileMfG map[string]string `json:"filemfg,omitempty"`
+	OS      map[string]string `json:"os,omitempty"`
+	OSMfg   map[string]string `json:"osmfg,omitempty"`
+	Prod    map[string]string `json:"prod,omitempty"`
+	ProdMfg map[string]string `json:"prodmfg,omitempty"`
 }
 
 func (aNSRL *ActionNSRL) GetWeight() uint {
 	return 100
 }
 
-func getStringMap(txn *badger.Txn, key string) ([]map[string]string, error) {
-	var result []map[string]string
-	item, err := txn.Get([]byte(key))
+// getFirstStringMap returns the first entry of key or nil. Errors are logged only.
+func (aNSRL *ActionNSRL) getFirstStringMap(key string) map[string]string {
+	r, err := aNSRL.store.Get(key)
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
-			return nil, nil
-		}
-		return nil, errors.Wrapf(err, "cannot get %s", key)
-	}
-	if err := item.Value(func(val []byte) error {
-		jsonStr, err := snappy.Decode(nil, val)
-		if err != nil {
-			return errors.Wrapf(err, "cannot decompress snappy of %s", key)
-		}
-		if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
-			return errors.Wrapf(err, "cannot unmarshal %s for %s", jsonStr, key)
-		}
+		aNSRL.logger.Error().Msgf("cannot get data of %s: %v", key, err)
 		return nil
-	}); err != nil {
-		return nil, errors.Wrapf(err, "cannot get data of %s", key)
 	}
-	return result, nil
+	if len(r) > 0 {
+		return r[0]
+	}
+	return nil
 }
 
-func (aNSRL *ActionNSRL) getNSRL(sha1sum string) (interface{}, []string, []string, error) {
+// getNSRL returns the NSRL entries of the SHA-1 checksum. If not found, the result is empty.
+func (aNSRL *ActionNSRL) getNSRL(sha1sum string) ([]ActionNSRLMeta, error) {
 	var result []ActionNSRLMeta
-	aNSRL.nsrldb.View(func(txn *badger.Txn) error {
-		item, err := txn.Get([]byte(NSRL_File + sha1sum))
-		if err != nil {
-			return errors.Wrapf(err, "cannot get os %s", NSRL_File+sha1sum)
+	// nsrl uses upper case hex values
+	key := NSRL_File + strings.ToUpper(sha1sum)
+	fileData, err := aNSRL.store.Get(key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get file data of %s", key)
+	}
+	if len(fileData) > nsrlMaxFileEntries {
+		fileData = fileData[0:nsrlMaxFileEntries]
+	}
+	for _, file := range fileData {
+		var am ActionNSRLMeta
+		am.File = file
+		if file["MfgCode"] != "" {
+			am.FileMfG = aNSRL.getFirstStringMap(NSRL_MFG + file["MfgCode"])
 		}
-		var fileData []map[string]string
-		if err := item.Value(func(val []byte) error {
-			jsonStr, err := snappy.Decode(nil, val)
-			if err != nil {
-				return errors.Wrapf(err, "cannot decompress snappy of %s", NSRL_File+sha1sum)
-			}
-			if err := json.Unmarshal([]byte(jsonStr), &fileData); err != nil {
-				return errors.Wrapf(err, "cannot unmarshal %s for %s", jsonStr, NSRL_File+sha1sum)
-			}
-			return nil
-		}); err != nil {
-			return errors.Wrapf(err, "cannot get value of %s", NSRL_File+sha1sum)
+		if file["ProductCode"] != "" {
+			am.Prod = aNSRL.getFirstStringMap(NSRL_PROD + file["ProductCode"])
 		}
-		if len(fileData) > 10 {
-			fileData = fileData[0:10]
+		if am.Prod["MfgCode"] != "" {
+			am.ProdMfg = aNSRL.getFirstStringMap(NSRL_MFG + am.Prod["MfgCode"])
 		}
-		for _, file := range fileData {
-			var am ActionNSRLMeta
-			am.File = file
-			if am.File["MfgCode"] != "" {
-				r, _ := getStringMap(txn, NSRL_MFG+am.Prod["MfgCode"])
-				if len(r) > 0 {
-					am.FileMfG = r[0]
-				}
-			}
-			r, err := getStringMap(txn, NSRL_PROD+file["ProductCode"])
-			if err != nil {
-				aNSRL.logger.Error().Msgf("cannot get data of %s: %v", NSRL_PROD+file["ProductCode"], err)
-				// return errors.Wrapf(err, "cannot get data of %s", NSRL_PROD+file["ProductCode"])
-			}
-			if len(r) > 0 {
-				am.Prod = r[0]
-			}
-			if am.Prod["MfgCode"] != "" {
-				r, _ = getStringMap(txn, NSRL_MFG+am.Prod["MfgCode"])
-				if len(r) > 0 {
-					am.ProdMfg = r[0]
-				}
-			}
-			r, err = getStringMap(txn, NSRL_OS+file["OpSystemCode"])
-			if err != nil {
-				aNSRL.logger.Error().Msgf("cannot get data of %s: %v", NSRL_OS+file["OpSystemCode"], err)
-				// return errors.Wrapf(err, "cannot get data of %s", NSRL_PROD+file["ProductCode"])
-			}
-			if len(r) > 0 {
-				am.OS = r[0]
-			}
-			if am.OS["MfgCode"] != "" {
-				r, _ = getStringMap(txn, NSRL_MFG+am.Prod["MfgCode"])
-				if len(r) > 0 {
-					am.OSMfg = r[0]
-				}
-			}
-			result = append(result, am)
+		// RDS v3 stores the operating system only in the product
+		osCode := file["OpSystemCode"]
+		if osCode == "" {
+			osCode = am.Prod["OpSystemCode"]
 		}
-		return nil
-	})
-	return result, []string{}, nil, nil
+		if osCode != "" {
+			am.OS = aNSRL.getFirstStringMap(NSRL_OS + osCode)
+		}
+		if am.OS["MfgCode"] != "" {
+			am.OSMfg = aNSRL.getFirstStringMap(NSRL_MFG + am.OS["MfgCode"])
+		}
+		result = append(result, am)
+	}
+	return result, nil
 }
 
 func (aNSRL *ActionNSRL) GetCaps() ActionCapability {
@@ -168,5 +187,6 @@ func (aNSRL *ActionNSRL) GetName() string {
 }
 
 var (
-	_ Action = &ActionNSRL{}
+	_ Action         = &ActionNSRL{}
+	_ ResultConsumer = &ActionNSRL{}
 )
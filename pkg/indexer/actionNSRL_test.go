@@ -0,0 +1,86 @@
+package indexer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	badger "github.com/dgraph-io/badger/v4"
+	"github.com/golang/snappy"
+	"github.com/je4/utils/v2/pkg/checksum"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// nsrlTestDB creates an in-memory badger database in the format of nsrl2badger
+func nsrlTestDB(t *testing.T, data map[string][]map[string]string) *badger.DB {
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		t.Fatalf("cannot open badger: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Update(func(txn *badger.Txn) error {
+		for key, list := range data {
+			d, err := json.Marshal(list)
+			if err != nil {
+				return err
+			}
+			if err := txn.Set([]byte(key), snappy.Encode(nil, d)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("cannot fill badger: %v", err)
+	}
+	return db
+}
+
+func TestActionNSRL_Stream(t *testing.T) {
+	const content = "hello nsrl"
+	// sha1 of content
+	sha1sum, err := checksum.Checksum(strings.NewReader(content), checksum.DigestSHA1)
+	assert.NoError(t, err)
+
+	db := nsrlTestDB(t, map[string][]map[string]string{
+		NSRL_File + strings.ToUpper(sha1sum): {{"FileName": "hello.txt", "ProductCode": "42", "OpSystemCode": "7"}},
+		NSRL_PROD + "42":                     {{"ProductName": "Hello Product", "MfgCode": "1"}},
+		NSRL_OS + "7":                        {{"OpSystemName": "Hello OS", "MfgCode": "1"}},
+		NSRL_MFG + "1":                       {{"MfgName": "Hello Inc."}},
+	})
+	logger := zerolog.Nop()
+
+	tests := []struct {
+		name      string
+		content   string
+		actions   []string
+		wantKnown bool
+	}{
+		{name: "known", content: content, actions: []string{NameNSRL}, wantKnown: true},
+		{name: "known with checksum", content: content, actions: []string{NameChecksum, NameNSRL}, wantKnown: true},
+		{name: "unknown", content: "something else", actions: []string{NameNSRL}, wantKnown: false},
+		{name: "unknown with checksum", content: "something else", actions: []string{NameChecksum, NameNSRL}, wantKnown: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ad := NewActionDispatcher(nil)
+			NewActionChecksum(NameChecksum, []checksum.DigestAlgorithm{checksum.DigestSHA1}, ad)
+			NewActionNSRL(NameNSRL, NewNSRLBadgerStore(db), ad, &logger)
+			result, err := ad.Stream(strings.NewReader(tt.content), []string{"hello.txt"}, tt.actions)
+			assert.NoError(t, err)
+			assert.Empty(t, result.Errors)
+			nsrlResult, ok := result.Metadata[NameNSRL].(*ActionNSRLResult)
+			if !assert.True(t, ok) {
+				return
+			}
+			assert.Equal(t, tt.wantKnown, nsrlResult.Known)
+			if tt.wantKnown {
+				assert.Len(t, nsrlResult.Entries, 1)
+				assert.Equal(t, "Hello Product", nsrlResult.Entries[0].Prod["ProductName"])
+				assert.Equal(t, "Hello OS", nsrlResult.Entries[0].OS["OpSystemName"])
+				assert.Equal(t, "Hello Inc.", nsrlResult.Entries[0].ProdMfg["MfgName"])
+				assert.Equal(t, "Hello Inc.", nsrlResult.Entries[0].OSMfg["MfgName"])
+			}
+		})
+	}
+}
@@ -79,7 +79,7 @@ func (as *ActionSiegfried) GetWeight() uint {
 }
 
 func (as *ActionSiegfried) GetCaps() ActionCapability {
-	return ACTFILEHEAD | ACTSTREAM
+	return ACTFILEHEAD | ACTSTREAM | ACTIDENT
 }
 
 func (as *ActionSiegfried) GetName() string {
@@ -85,16 +85,25 @@ func (at *ActionTika) GetName() string {
 }
 
 func (at *ActionTika) Stream(contentType string, reader io.Reader, filename string) (*ResultV2, error) {
+	return at.StreamContext(context.Background(), contentType, reader, filename)
+}
+
+func (at *ActionTika) DoV2(filename string) (*ResultV2, error) {
+	return at.DoV2Context(context.Background(), filename)
+}
+
+func (at *ActionTika) StreamContext(ctx context.Context, contentType string, reader io.Reader, filename string) (*ResultV2, error) {
 	if !at.CanHandle(contentType, filename) {
 		return nil, nil
 	}
 	client := &http.Client{}
-	ctx, cancel := context.WithTimeout(context.Background(), at.timeout)
+	ctx, cancel := context.WithTimeout(ctx, at.timeout)
 	defer cancel()
 	req, err := http.NewRequestWithContext(ctx, http.MethodPut, at.url, reader)
 	if err != nil {
 		return nil, errors.Wrapf(err, "cannot create tika request - %v", at.url)
 	}
+	injectTrace(ctx, req)
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
 	//req.Header.Add("fileUrl", uri.String())
@@ -109,7 +118,7 @@ func (at *ActionTika) Stream(contentType string, reader io.Reader, filename stri
 	}
 
 	if tresp.StatusCode != http.StatusOK {
-		return nil, errors.New(fmt.Sprintf("status not ok - %v -> %v: %s", at.url, tresp.Status, string(bodyBytes)))
+		return nil, errors.WithMessagef(httpStatusError(tresp.StatusCode), "status not ok - %v -> %v: %s", at.url, tresp.Status, string(bodyBytes))
 	}
 
 	if bodyBytes[0] == '{' {
@@ -150,19 +159,20 @@ func (at *ActionTika) Stream(contentType string, reader io.Reader, filename stri
 	return result, nil
 }
 
-func (at *ActionTika) DoV2(filename string) (*ResultV2, error) {
+func (at *ActionTika) DoV2Context(ctx context.Context, filename string) (*ResultV2, error) {
 	reader, err := os.Open(filename)
 	if err != nil {
 		return nil, errors.Wrapf(err, "cannot open file '%s'", filename)
 	}
 	defer reader.Close()
 	client := &http.Client{}
-	ctx, cancel := context.WithTimeout(context.Background(), at.timeout)
+	ctx, cancel := context.WithTimeout(ctx, at.timeout)
 	defer cancel()
 	req, err := http.NewRequestWithContext(ctx, http.MethodPut, at.url, reader)
 	if err != nil {
 		return nil, errors.Wrapf(err, "cannot create tika request - %v", at.url)
 	}
+	injectTrace(ctx, req)
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
 	//req.Header.Add("fileUrl", uri.String())
@@ -177,7 +187,7 @@ func (at *ActionTika) DoV2(filename string) (*ResultV2, error) {
 	}
 
 	if tresp.StatusCode != http.StatusOK {
-		return nil, errors.New(fmt.Sprintf("status not ok - %v -> %v: %s", at.url, tresp.Status, string(bodyBytes)))
+		return nil, errors.WithMessagef(httpStatusError(tresp.StatusCode), "status not ok - %v -> %v: %s", at.url, tresp.Status, string(bodyBytes))
 	}
 
 	if bodyBytes[0] == '{' {
@@ -212,5 +222,6 @@ func (at *ActionTika) DoV2(filename string) (*ResultV2, error) {
 }
 
 var (
-	_ Action = (*ActionTika)(nil)
+	_ Action        = (*ActionTika)(nil)
+	_ ContextAction = (*ActionTika)(nil)
 )
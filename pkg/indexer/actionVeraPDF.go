@@ -0,0 +1,340 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+)
+
+// VeraPDFResult is the conformance result of veraPDF with one report per validated flavour
+type VeraPDFResult struct {
+	Compliant bool `json:"compliant"`
+	// ClaimedFlavours are the flavours claimed by the XMP metadata of the PDF, e.g. ["PDF/A-2A", "PDF/UA-1"]
+	ClaimedFlavours []string `json:"claimedflavours,omitempty"`
+	// NoClaim is set, if the PDF claims no flavour. veraPDF validates against its default profile then.
+	NoClaim bool            `json:"noclaim,omitempty"`
+	Reports []VeraPDFReport `json:"reports"`
+}
+
+type VeraPDFReport struct {
+	// Flavour is the flavour of the validation profile, e.g. "PDF/A-2B"
+	Flavour      string        `json:"flavour"`
+	Profile      string        `json:"profile"`
+	Compliant    bool          `json:"compliant"`
+	Statement    string        `json:"statement,omitempty"`
+	PassedRules  int           `json:"passedrules"`
+	FailedRules  int           `json:"failedrules"`
+	PassedChecks int           `json:"passedchecks"`
+	FailedChecks int           `json:"failedchecks"`
+	Rules        []VeraPDFRule `json:"rules,omitempty"`
+}
+
+// VeraPDFRule is a failed rule of the validation profile
+type VeraPDFRule struct {
+	Specification string `json:"specification"`
+	Clause        string `json:"clause"`
+	TestNumber    int    `json:"testnumber"`
+	Description   string `json:"description,omitempty"`
+	Object        string `json:"object,omitempty"`
+	FailedChecks  int    `json:"failedchecks"`
+}
+
+// veraPDFMRR is the part of the machine readable report of veraPDF, which is used
+type veraPDFMRR struct {
+	Jobs []struct {
+		ValidationReports []veraPDFValidationReport `xml:"validationReport"`
+		// newer versions group the reports of several flavours
+		GroupedReports []veraPDFValidationReport `xml:"validationReports>validationReport"`
+		TaskException  *struct {
+			Type    string `xml:"type,attr"`
+			Message string `xml:"exceptionMessage"`
+		} `xml:"taskException"`
+	} `xml:"jobs>job"`
+}
+
+type veraPDFValidationReport struct {
+	ProfileName string `xml:"profileName,attr"`
+	Statement   string `xml:"statement,attr"`
+	IsCompliant bool   `xml:"isCompliant,attr"`
+	Details     struct {
+		PassedRules  int `xml:"passedRules,attr"`
+		FailedRules  int `xml:"failedRules,attr"`
+		PassedChecks int `xml:"passedChecks,attr"`
+		FailedChecks int `xml:"failedChecks,attr"`
+		Rules        []struct {
+			Specification string `xml:"specification,attr"`
+			Clause        string `xml:"clause,attr"`
+			TestNumber    int    `xml:"testNumber,attr"`
+			Status        string `xml:"status,attr"`
+			FailedChecks  int    `xml:"failedChecks,attr"`
+			Description   string `xml:"description"`
+			Object        string `xml:"object"`
+		} `xml:"rule"`
+	} `xml:"details"`
+}
+
+// the PDF/A and PDF/UA identification schemas of the XMP metadata as element or attribute
+var (
+	regexpPDFAPart        = regexp.MustCompile(`pdfaid:part(?:=["']|>)\s*(\d+)`)
+	regexpPDFAConformance = regexp.MustCompile(`pdfaid:conformance(?:=["']|>)\s*([A-Za-z])`)
+	regexpPDFUAPart       = regexp.MustCompile(`pdfuaid:part(?:=["']|>)\s*(\d+)`)
+)
+
+// veraPDFClaimChunk is the read size for the search of the XMP claims, the overlap covers matches across chunks
+const (
+	veraPDFClaimChunk   = 1 << 20
+	veraPDFClaimOverlap = 256
+)
+
+// veraPDFClaims returns the flavours claimed by the XMP metadata of the PDF.
+// Only unfiltered metadata streams are found, which PDF/A requires for the document metadata.
+func veraPDFClaims(filename string) ([]string, error) {
+	fp, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open '%s'", filename)
+	}
+	defer fp.Close()
+	var part, conformance, uaPart string
+	find := func(re *regexp.Regexp, data []byte, value *string) {
+		if *value != "" {
+			return
+		}
+		if match := re.FindSubmatch(data); match != nil {
+			*value = string(match[1])
+		}
+	}
+	buf := make([]byte, veraPDFClaimChunk+veraPDFClaimOverlap)
+	var keep int
+	for {
+		n, err := io.ReadFull(fp, buf[keep:keep+veraPDFClaimChunk])
+		data := buf[:keep+n]
+		find(regexpPDFAPart, data, &part)
+		find(regexpPDFAConformance, data, &conformance)
+		find(regexpPDFUAPart, data, &uaPart)
+		if err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			return nil, errors.Wrapf(err, "cannot read '%s'", filename)
+		}
+		keep = copy(buf, data[len(data)-veraPDFClaimOverlap:])
+	}
+	var claims []string
+	if part != "" {
+		claims = append(claims, "PDF/A-"+part+strings.ToUpper(conformance))
+	}
+	if uaPart != "" {
+		claims = append(claims, "PDF/UA-"+uaPart)
+	}
+	return claims, nil
+}
+
+type ActionVeraPDF struct {
+	name    string
+	verapdf string
+	flavour string
+	wsl     bool
+	timeout time.Duration
+	tempDir string
+	caps    ActionCapability
+}
+
+// NewActionVeraPDF creates an action, which checks the PDF/A and PDF/UA conformance of PDFs with veraPDF.
+// Without flavour (e.g. "1b", "2u", "ua1"), the flavour is detected from the XMP metadata of the PDF.
+// The claimed flavours are reported separately from the validated ones, a PDF without claim is marked as NoClaim.
+// Streams are spooled to tempDir.
+func NewActionVeraPDF(name, verapdf, flavour string, wsl bool, timeout time.Duration, tempDir string, ad *ActionDispatcher) Action {
+	if name == "" {
+		name = NameVeraPDF
+	}
+	if timeout == 0 {
+		timeout = time.Second * 120
+	}
+	av := &ActionVeraPDF{
+		name:    name,
+		verapdf: verapdf,
+		flavour: flavour,
+		wsl:     wsl,
+		timeout: timeout,
+		tempDir: tempDir,
+		caps:    ACTFILEFULL | ACTSTREAM,
+	}
+	ad.RegisterAction(av)
+	return av
+}
+
+func (av *ActionVeraPDF) CanHandle(contentType string, filename string) bool {
+	mimetype, _, _ := strings.Cut(contentType, ";")
+	if strings.TrimSpace(mimetype) == "application/pdf" {
+		return true
+	}
+	return strings.ToLower(filepath.Ext(filename)) == ".pdf"
+}
+
+func (av *ActionVeraPDF) Stream(contentType string, reader io.Reader, filename string) (*ResultV2, error) {
+	return av.StreamContext(context.Background(), contentType, reader, filename)
+}
+
+func (av *ActionVeraPDF) DoV2(filename string) (*ResultV2, error) {
+	return av.DoV2Context(context.Background(), filename)
+}
+
+func (av *ActionVeraPDF) StreamContext(ctx context.Context, contentType string, reader io.Reader, filename string) (*ResultV2, error) {
+	if !av.CanHandle(contentType, filename) {
+		return nil, nil
+	}
+	tmpFile, err := os.CreateTemp(av.tempDir, "verapdf-*.pdf")
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create temporary file")
+	}
+	tmpName := tmpFile.Name()
+	defer os.Remove(tmpName)
+	if _, err := io.Copy(tmpFile, reader); err != nil {
+		tmpFile.Close()
+		return nil, errors.Wrapf(err, "cannot write data of '%s' to '%s'", filename, tmpName)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return nil, errors.Wrapf(err, "cannot close temporary file '%s'", tmpName)
+	}
+	return av.validate(ctx, tmpName)
+}
+
+func (av *ActionVeraPDF) DoV2Context(ctx context.Context, filename string) (*ResultV2, error) {
+	// without a content type, the pdf is recognized by the identified pronoms or the extension
+	isPDF := slices.ContainsFunc(stagePronoms(ctx), func(pronom string) bool { return slices.Contains(pdfPronoms, pronom) })
+	if !isPDF && !av.CanHandle("", filename) {
+		return nil, nil
+	}
+	return av.validate(ctx, filename)
+}
+
+func (av *ActionVeraPDF) validate(ctx context.Context, filename string) (*ResultV2, error) {
+	path := filename
+	if av.wsl {
+		path = pathToWSL(filename)
+	}
+	cmdparam := []string{"--format", "mrr"}
+	if av.flavour != "" {
+		cmdparam = append(cmdparam, "--flavour", av.flavour)
+	}
+	cmdparam = append(cmdparam, path)
+	cmdfile := av.verapdf
+	if av.wsl {
+		cmdparam = append([]string{cmdfile}, cmdparam...)
+		cmdfile = "wsl"
+	}
+
+	var out, errOut bytes.Buffer
+	ctx, cancel := context.WithTimeout(ctx, av.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, cmdfile, cmdparam...)
+	cmd.WaitDelay = commandWaitDelay
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+
+	// veraPDF exits with 1, if the file is not compliant, so the report is parsed anyway
+	runErr := runCommand(ctx, cmd)
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(runErr, &exitErr) || ctx.Err() != nil {
+			return nil, errors.Wrapf(commandError(ctx, runErr), "error executing (%s %s) for file '%s': %v", cmdfile, cmdparam, filename, errOut.String())
+		}
+	}
+
+	var mrr veraPDFMRR
+	if err := xml.Unmarshal(out.Bytes(), &mrr); err != nil {
+		if runErr != nil {
+			return nil, errors.Wrapf(commandError(ctx, runErr), "error executing (%s %s) for file '%s': %v", cmdfile, cmdparam, filename, errOut.String())
+		}
+		return nil, errors.Wrapf(err, "cannot unmarshal verapdf report: %s", out.String())
+	}
+	if len(mrr.Jobs) == 0 {
+		return nil, errors.Errorf("no verapdf result for file '%s': %s", filename, errOut.String())
+	}
+	job := mrr.Jobs[0]
+	reports := slices.Concat(job.ValidationReports, job.GroupedReports)
+	if len(reports) == 0 {
+		if job.TaskException != nil {
+			return nil, errors.Errorf("verapdf cannot validate file '%s': %s %s", filename, job.TaskException.Type, job.TaskException.Message)
+		}
+		return nil, errors.Errorf("no verapdf validation report for file '%s'", filename)
+	}
+
+	claims, err := veraPDFClaims(filename)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	vr := &VeraPDFResult{Compliant: true, ClaimedFlavours: claims, NoClaim: len(claims) == 0}
+	for _, report := range reports {
+		r := VeraPDFReport{
+			Flavour:      strings.TrimSuffix(report.ProfileName, " validation profile"),
+			Profile:      report.ProfileName,
+			Compliant:    report.IsCompliant,
+			Statement:    report.Statement,
+			PassedRules:  report.Details.PassedRules,
+			FailedRules:  report.Details.FailedRules,
+			PassedChecks: report.Details.PassedChecks,
+			FailedChecks: report.Details.FailedChecks,
+		}
+		for _, rule := range report.Details.Rules {
+			if rule.Status != "failed" {
+				continue
+			}
+			r.Rules = append(r.Rules, VeraPDFRule{
+				Specification: rule.Specification,
+				Clause:        rule.Clause,
+				TestNumber:    rule.TestNumber,
+				Description:   strings.TrimSpace(rule.Description),
+				Object:        rule.Object,
+				FailedChecks:  rule.FailedChecks,
+			})
+		}
+		vr.Compliant = vr.Compliant && r.Compliant
+		vr.Reports = append(vr.Reports, r)
+	}
+
+	result := NewResultV2()
+	result.Metadata[av.GetName()] = vr
+	return result, nil
+}
+
+func (av *ActionVeraPDF) GetWeight() uint {
+	return 70
+}
+
+func (av *ActionVeraPDF) GetCaps() ActionCapability {
+	return av.caps
+}
+
+func (av *ActionVeraPDF) GetName() string {
+	return av.name
+}
+
+var (
+	_ Action        = &ActionVeraPDF{}
+	_ ContextAction = &ActionVeraPDF{}
+)
@@ -0,0 +1,172 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// veraPDFScript prints a machine readable report, the flavour is claimed by the xmp metadata or given with --flavour.
+// PDFs with a claim are PDF/A-2B compliant.
+const veraPDFScript = `#!/bin/sh
+# --format mrr [--flavour <flavour>] <file>
+if [ "$3" = "--flavour" ]; then
+  profile="PDF/A-$(echo $4 | tr a-z A-Z) validation profile"
+  cat <<EOF
+<?xml version="1.0" encoding="utf-8"?>
+<report><jobs><job>
+  <validationReport jobEndStatus="normal" profileName="$profile" statement="PDF file is not compliant with Validation Profile requirements." isCompliant="false">
+    <details passedRules="100" failedRules="1" passedChecks="1000" failedChecks="2">
+      <rule specification="ISO 19005-1:2005" clause="6.3.5" testNumber="2" status="failed" failedChecks="2">
+        <description>All fonts used in a conforming file shall be embedded</description>
+        <object>PDFont</object>
+        <check status="failed"><context>root/document[0]</context></check>
+      </rule>
+    </details>
+  </validationReport>
+</job></jobs></report>
+EOF
+  exit 1
+fi
+if grep -q broken "$3"; then
+  cat <<EOF
+<?xml version="1.0" encoding="utf-8"?>
+<report><jobs><job>
+  <taskException type="PARSE" isExecuted="true" isSuccess="false"><exceptionMessage>Couldn't parse stream</exceptionMessage></taskException>
+</job></jobs></report>
+EOF
+  exit 7
+fi
+# without claim, veraPDF validates against its default profile PDF/A-1B
+if ! grep -q "pdfaid:part" "$3"; then
+  cat <<EOF
+<?xml version="1.0" encoding="utf-8"?>
+<report><jobs><job>
+  <validationReport jobEndStatus="normal" profileName="PDF/A-1B validation profile" statement="PDF file is not compliant with Validation Profile requirements." isCompliant="false">
+    <details passedRules="100" failedRules="1" passedChecks="1000" failedChecks="1">
+      <rule specification="ISO 19005-1:2005" clause="6.7.2" testNumber="1" status="failed" failedChecks="1">
+        <description>The document catalog dictionary shall contain the Metadata key</description>
+        <object>CosDocument</object>
+      </rule>
+    </details>
+  </validationReport>
+</job></jobs></report>
+EOF
+  exit 1
+fi
+cat <<EOF
+<?xml version="1.0" encoding="utf-8"?>
+<report><jobs><job>
+  <validationReport jobEndStatus="normal" profileName="PDF/A-2B validation profile" statement="PDF file is compliant with Validation Profile requirements." isCompliant="true">
+    <details passedRules="140" failedRules="0" passedChecks="2000" failedChecks="0"></details>
+  </validationReport>
+</job></jobs></report>
+EOF
+`
+
+func TestActionVeraPDF(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script as verapdf")
+	}
+	folder := t.TempDir()
+	verapdf := filepath.Join(folder, "verapdf")
+	assert.NoError(t, os.WriteFile(verapdf, []byte(veraPDFScript), 0755))
+
+	const xmp = `<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:Description pdfaid:part="2" pdfaid:conformance="B"><pdfuaid:part>1</pdfuaid:part></rdf:Description></x:xmpmeta>`
+	tests := []struct {
+		name          string
+		flavour       string
+		data          string
+		wantFlavour   string
+		wantClaims    []string
+		wantCompliant bool
+		wantRule      string
+		wantErr       bool
+	}{
+		{name: "auto", data: "%PDF-1.7 " + xmp, wantFlavour: "PDF/A-2B", wantClaims: []string{"PDF/A-2B", "PDF/UA-1"}, wantCompliant: true},
+		{name: "flavour", flavour: "1b", data: "%PDF-1.4 " + xmp, wantFlavour: "PDF/A-1B", wantClaims: []string{"PDF/A-2B", "PDF/UA-1"}, wantRule: "6.3.5"},
+		{name: "no claim", data: "%PDF-1.4", wantFlavour: "PDF/A-1B", wantRule: "6.7.2"},
+		{name: "broken", data: "%PDF-1.4 broken", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ad := NewActionDispatcher(nil)
+			action := NewActionVeraPDF("", verapdf, tt.flavour, false, 0, folder, ad).(*ActionVeraPDF)
+			assert.False(t, action.CanHandle("text/plain", "test.txt"))
+			result, err := action.StreamContext(context.Background(), "application/pdf", strings.NewReader(tt.data), "test")
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "Couldn't parse stream")
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			vr, ok := result.Metadata[NameVeraPDF].(*VeraPDFResult)
+			if !assert.True(t, ok) || !assert.Len(t, vr.Reports, 1) {
+				return
+			}
+			assert.Equal(t, tt.wantCompliant, vr.Compliant)
+			assert.Equal(t, tt.wantFlavour, vr.Reports[0].Flavour)
+			assert.Equal(t, tt.wantClaims, vr.ClaimedFlavours)
+			assert.Equal(t, len(tt.wantClaims) == 0, vr.NoClaim)
+			if tt.wantRule != "" && assert.Len(t, vr.Reports[0].Rules, 1) {
+				assert.Equal(t, tt.wantRule, vr.Reports[0].Rules[0].Clause)
+			}
+		})
+	}
+}
+
+func TestActionVeraPDF_DoV2Context(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script as verapdf")
+	}
+	folder := t.TempDir()
+	verapdf := filepath.Join(folder, "verapdf")
+	assert.NoError(t, os.WriteFile(verapdf, []byte(veraPDFScript), 0755))
+	action := NewActionVeraPDF("", verapdf, "", false, 0, folder, NewActionDispatcher(nil)).(*ActionVeraPDF)
+	for _, name := range []string{"test.pdf", "test.txt", "test"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(folder, name), []byte("%PDF-1.4"), 0644))
+	}
+
+	// files, which are no pdfs, are ignored
+	result, err := action.DoV2Context(context.Background(), filepath.Join(folder, "test.txt"))
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	result, err = action.DoV2Context(context.Background(), filepath.Join(folder, "test.pdf"))
+	if assert.NoError(t, err) && assert.NotNil(t, result) {
+		assert.Contains(t, result.Metadata, NameVeraPDF)
+	}
+	// the identified pronom is used without extension
+	result, err = action.DoV2Context(withPronoms(context.Background(), []string{"fmt/18"}), filepath.Join(folder, "test"))
+	if assert.NoError(t, err) && assert.NotNil(t, result) {
+		assert.Contains(t, result.Metadata, NameVeraPDF)
+	}
+}
+
+func TestVeraPDFClaims(t *testing.T) {
+	folder := t.TempDir()
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{name: "element", data: "<pdfaid:part>3</pdfaid:part><pdfaid:conformance>a</pdfaid:conformance>", want: []string{"PDF/A-3A"}},
+		{name: "without conformance", data: "<rdf:Description pdfaid:part='4'/>", want: []string{"PDF/A-4"}},
+		{name: "across chunks", data: strings.Repeat(" ", veraPDFClaimChunk-10) + `pdfaid:part="1" pdfaid:conformance="B"`, want: []string{"PDF/A-1B"}},
+		{name: "no claim", data: "%PDF-1.4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(folder, "test.pdf")
+			assert.NoError(t, os.WriteFile(filename, []byte(tt.data), 0644))
+			claims, err := veraPDFClaims(filename)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, claims)
+		})
+	}
+}
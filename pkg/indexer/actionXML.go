@@ -77,7 +77,7 @@ func (as *ActionXML) GetWeight() uint {
 }
 
 func (as *ActionXML) GetCaps() ActionCapability {
-	return ACTFILEHEAD | ACTSTREAM
+	return ACTFILEHEAD | ACTSTREAM | ACTIDENT
 }
 
 func (as *ActionXML) GetName() string {
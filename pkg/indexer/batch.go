@@ -0,0 +1,240 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package indexer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"path"
+	"sync"
+
+	"emperror.dev/errors"
+)
+
+// BatchResult is one line of the jsonl output of a batch
+type BatchResult struct {
+	Path   string    `json:"path"`
+	Error  string    `json:"error,omitempty"`
+	Result *ResultV2 `json:"result,omitempty"`
+}
+
+// Batch indexes all files of a fs.FS with a bounded number of workers
+type Batch struct {
+	ad      *ActionDispatcher
+	actions []string
+	include []string
+	exclude []string
+	maxSize int64
+	workers int
+	head    bool
+}
+
+// NewBatch creates a batch indexer. Include and exclude are path.Match globs,
+// which are matched against the base name and the path within the fs.
+// A maxSize of 0 means no limit. With head, only the head of the files is indexed.
+func NewBatch(ad *ActionDispatcher, actions []string, include, exclude []string, maxSize int64, workers int, head bool) (*Batch, error) {
+	for _, glob := range append(append([]string{}, include...), exclude...) {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, errors.Wrapf(err, "invalid glob '%s'", glob)
+		}
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return &Batch{
+		ad:      ad,
+		actions: actions,
+		include: include,
+		exclude: exclude,
+		maxSize: maxSize,
+		workers: workers,
+		head:    head,
+	}, nil
+}
+
+func matchGlob(globs []string, name string) bool {
+	for _, glob := range globs {
+		if ok, _ := path.Match(glob, path.Base(name)); ok {
+			return true
+		}
+		if ok, _ := path.Match(glob, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadBatchDone returns the paths of a previous jsonl output, which have a result. Incomplete lines and errors
+// are ignored, so that these files are indexed again. Partial results of head only runs are done only for head.
+func ReadBatchDone(r io.Reader, head bool) (map[string]bool, error) {
+	done := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var br BatchResult
+		if err := json.Unmarshal(scanner.Bytes(), &br); err != nil {
+			continue
+		}
+		if br.Result != nil && (head || !br.Result.Partial) {
+			done[br.Path] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot read batch output")
+	}
+	return done, nil
+}
+
+// ReadBatchResults returns the results of a jsonl output. Incomplete lines are ignored.
+// If a path has been indexed again on resume, the last line of the path is used.
+func ReadBatchResults(r io.Reader) ([]*BatchResult, error) {
+	var results []*BatchResult
+	index := map[string]int{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		br := &BatchResult{}
+		if err := json.Unmarshal(scanner.Bytes(), br); err != nil {
+			continue
+		}
+		if i, ok := index[br.Path]; ok {
+			results[i] = br
+			continue
+		}
+		index[br.Path] = len(results)
+		results = append(results, br)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot read batch output")
+	}
+	return results, nil
+}
+
+// Run walks fsys from root and writes one json line per file to w.
+// Files contained in done are skipped. If ctx is done, the running files are aborted
+// without writing their results, so that they are indexed again on resume.
+func (b *Batch) Run(ctx context.Context, fsys fs.FS, root string, w io.Writer, done map[string]bool) error {
+	var (
+		wg       sync.WaitGroup
+		lock     sync.Mutex
+		writeErr error
+		jobs     = make(chan string)
+		enc      = json.NewEncoder(w)
+	)
+	write := func(br *BatchResult) {
+		lock.Lock()
+		defer lock.Unlock()
+		if writeErr != nil {
+			return
+		}
+		if err := enc.Encode(br); err != nil {
+			writeErr = errors.Wrapf(err, "cannot write result of %s", br.Path)
+		}
+	}
+	for i := 0; i < b.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range jobs {
+				br := b.index(ctx, fsys, name)
+				if ctx.Err() != nil {
+					continue
+				}
+				write(br)
+			}
+		}()
+	}
+
+	walkErr := fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		lock.Lock()
+		stop := writeErr != nil
+		lock.Unlock()
+		if stop {
+			return fs.SkipAll
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			write(&BatchResult{Path: name, Error: err.Error()})
+			return nil
+		}
+		if matchGlob(b.exclude, name) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		if len(b.include) > 0 && !matchGlob(b.include, name) {
+			return nil
+		}
+		if done[name] {
+			return nil
+		}
+		if b.maxSize > 0 {
+			info, err := d.Info()
+			if err != nil {
+				write(&BatchResult{Path: name, Error: err.Error()})
+				return nil
+			}
+			if info.Size() > b.maxSize {
+				return nil
+			}
+		}
+		select {
+		case jobs <- name:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return nil
+	})
+	close(jobs)
+	wg.Wait()
+	if walkErr != nil {
+		return errors.Wrapf(walkErr, "cannot walk %s", root)
+	}
+	return writeErr
+}
+
+func (b *Batch) index(ctx context.Context, fsys fs.FS, name string) *BatchResult {
+	br := &BatchResult{Path: name}
+	fp, err := fsys.Open(name)
+	if err != nil {
+		br.Error = err.Error()
+		return br
+	}
+	defer fp.Close()
+	var result *ResultV2
+	if b.head {
+		var size int64 = -1
+		if info, err := fp.Stat(); err == nil {
+			size = info.Size()
+		}
+		result, err = b.ad.HeadContext(ctx, fp, size, []string{path.Base(name)}, b.actions)
+	} else {
+		result, err = b.ad.StreamContext(ctx, fp, []string{path.Base(name)}, b.actions)
+	}
+	if err != nil {
+		br.Error = err.Error()
+		return br
+	}
+	br.Result = result
+	return br
+}
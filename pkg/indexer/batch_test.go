@@ -0,0 +1,96 @@
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/je4/utils/v2/pkg/checksum"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatch(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a/one.txt":       {Data: []byte("one")},
+		"a/two.txt":       {Data: []byte("two")},
+		"a/big.txt":       {Data: []byte("0123456789")},
+		"a/image.tif":     {Data: []byte("tif")},
+		"skip/three.txt":  {Data: []byte("three")},
+		"b/sub/four.txt":  {Data: []byte("four")},
+		"b/sub/five.json": {Data: []byte("{}")},
+	}
+	ad := NewActionDispatcher(nil)
+	NewActionChecksum("checksum", []checksum.DigestAlgorithm{checksum.DigestMD5}, ad)
+
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		maxSize int64
+		done    []string
+		want    []string
+	}{
+		{name: "all", want: []string{"a/big.txt", "a/image.tif", "a/one.txt", "a/two.txt", "b/sub/five.json", "b/sub/four.txt", "skip/three.txt"}},
+		{name: "include", include: []string{"*.txt"}, want: []string{"a/big.txt", "a/one.txt", "a/two.txt", "b/sub/four.txt", "skip/three.txt"}},
+		{name: "exclude dir", include: []string{"*.txt"}, exclude: []string{"skip"}, want: []string{"a/big.txt", "a/one.txt", "a/two.txt", "b/sub/four.txt"}},
+		{name: "max size", include: []string{"*.txt"}, maxSize: 5, want: []string{"a/one.txt", "a/two.txt", "b/sub/four.txt", "skip/three.txt"}},
+		{name: "resume", include: []string{"a/*"}, done: []string{"a/one.txt"}, want: []string{"a/big.txt", "a/image.tif", "a/two.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := NewBatch(ad, []string{"checksum"}, tt.include, tt.exclude, tt.maxSize, 3, false)
+			if !assert.NoError(t, err) {
+				return
+			}
+			var prev bytes.Buffer
+			for _, name := range tt.done {
+				assert.NoError(t, b.Run(context.Background(), fsys, name, &prev, nil))
+			}
+			done, err := ReadBatchDone(bytes.NewReader(prev.Bytes()), false)
+			assert.NoError(t, err)
+
+			var out bytes.Buffer
+			assert.NoError(t, b.Run(context.Background(), fsys, ".", &out, done))
+			result, err := ReadBatchDone(&out, false)
+			assert.NoError(t, err)
+			var paths []string
+			for p := range result {
+				paths = append(paths, p)
+			}
+			slices.Sort(paths)
+			assert.Equal(t, tt.want, paths)
+		})
+	}
+}
+
+func TestReadBatchDone(t *testing.T) {
+	output := `{"path": "error.txt", "error": "cannot open"}
+{"path": "partial.txt", "result": {"mimetype": "text/plain", "partial": true}}
+{"path": "full.txt", "result": {"mimetype": "text/plain"}}
+{"path": "retried.txt", "error": "cannot open"}
+{"path": "retried.txt", "result": {"mimetype": "text/plain"}}
+{"path": "incomplete.txt", "res`
+
+	done, err := ReadBatchDone(strings.NewReader(output), false)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"full.txt": true, "retried.txt": true}, done)
+
+	done, err = ReadBatchDone(strings.NewReader(output), true)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"partial.txt": true, "full.txt": true, "retried.txt": true}, done)
+
+	results, err := ReadBatchResults(strings.NewReader(output))
+	assert.NoError(t, err)
+	if assert.Len(t, results, 4) {
+		assert.Equal(t, "retried.txt", results[3].Path)
+		assert.NotNil(t, results[3].Result)
+	}
+}
+
+func TestBatchInvalidGlob(t *testing.T) {
+	_, err := NewBatch(NewActionDispatcher(nil), nil, []string{"[a"}, nil, 0, 1, false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,206 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package indexer
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+)
+
+// default values of clamd.conf
+const clamdDefaultChunkSize = 64 * 1024
+const clamdDefaultStreamMaxLength = 25 * 1024 * 1024
+
+// ClamdClient talks to a running clamd via its socket protocol
+type ClamdClient struct {
+	network         string
+	address         string
+	timeout         time.Duration
+	chunkSize       int
+	streamMaxLength int64
+}
+
+// parseClamdAddress accepts "unix:///path", "unix:/path", "tcp://host:port", "/path" and "host:port"
+func parseClamdAddress(address string) (network string, addr string, err error) {
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		return "unix", strings.TrimPrefix(address, "unix://"), nil
+	case strings.HasPrefix(address, "unix:"):
+		return "unix", strings.TrimPrefix(address, "unix:"), nil
+	case strings.HasPrefix(address, "tcp://"):
+		return "tcp", strings.TrimPrefix(address, "tcp://"), nil
+	case strings.HasPrefix(address, "/"):
+		return "unix", address, nil
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return "", "", errors.Wrapf(err, "invalid clamd address '%s'", address)
+	}
+	return "tcp", address, nil
+}
+
+func NewClamdClient(address string, timeout time.Duration, streamMaxLength int64) (*ClamdClient, error) {
+	network, addr, err := parseClamdAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	if streamMaxLength <= 0 {
+		streamMaxLength = clamdDefaultStreamMaxLength
+	}
+	if timeout == 0 {
+		timeout = time.Second * 60
+	}
+	return &ClamdClient{
+		network:         network,
+		address:         addr,
+		timeout:         timeout,
+		chunkSize:       clamdDefaultChunkSize,
+		streamMaxLength: streamMaxLength,
+	}, nil
+}
+
+func (cc *ClamdClient) String() string {
+	return fmt.Sprintf("%s://%s", cc.network, cc.address)
+}
+
+func (cc *ClamdClient) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, cc.network, cc.address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot connect to clamd at %s", cc)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return nil, errors.Wrapf(err, "cannot set deadline for %s", cc)
+		}
+	}
+	return conn, nil
+}
+
+// command sends a simple null terminated command and returns the reply
+func (cc *ClamdClient) command(cmd string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cc.timeout)
+	defer cancel()
+	conn, err := cc.dial(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("z" + cmd + "\x00")); err != nil {
+		return "", errors.Wrapf(err, "cannot send %s to %s", cmd, cc)
+	}
+	return readClamdReply(conn)
+}
+
+func readClamdReply(r io.Reader) (string, error) {
+	reply, err := bufio.NewReader(r).ReadString('\x00')
+	if err != nil && !(err == io.EOF && reply != "") {
+		return "", errors.Wrap(err, "cannot read clamd reply")
+	}
+	return strings.TrimSpace(strings.TrimRight(reply, "\x00")), nil
+}
+
+// Ping checks whether clamd is alive
+func (cc *ClamdClient) Ping() error {
+	reply, err := cc.command("PING")
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return errors.Errorf("invalid PING reply from %s: %s", cc, reply)
+	}
+	return nil
+}
+
+// Version returns the version string of clamd and its signatures
+func (cc *ClamdClient) Version() (string, error) {
+	return cc.command("VERSION")
+}
+
+// InStream sends the content of reader with the INSTREAM command.
+// Data exceeding StreamMaxLength is not sent, the result is marked as error.
+func (cc *ClamdClient) InStream(reader io.Reader) (*ClamAVResult, error) {
+	return cc.InStreamContext(context.Background(), reader)
+}
+
+// InStreamContext is InStream, which closes the connection if ctx is done
+func (cc *ClamdClient) InStreamContext(ctx context.Context, reader io.Reader) (*ClamAVResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, cc.timeout)
+	defer cancel()
+	conn, err := cc.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, errors.Wrapf(err, "cannot send INSTREAM to %s", cc)
+	}
+	var sent int64
+	var exceeded bool
+	var writeErr error
+	buf := make([]byte, 4+cc.chunkSize)
+	for {
+		n, err := io.ReadFull(reader, buf[4:])
+		if n > 0 {
+			if sent+int64(n) > cc.streamMaxLength {
+				exceeded = true
+				break
+			}
+			binary.BigEndian.PutUint32(buf[:4], uint32(n))
+			if _, writeErr = conn.Write(buf[:4+n]); writeErr != nil {
+				// clamd closes the connection on errors, but sends a reply
+				break
+			}
+			sent += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot read data for %s", cc)
+		}
+	}
+	if exceeded {
+		return &ClamAVResult{
+			Status:     ClamAVError,
+			Signatures: []string{},
+			Message:    fmt.Sprintf("stream exceeds StreamMaxLength of %d bytes", cc.streamMaxLength),
+		}, nil
+	}
+	if writeErr == nil {
+		// zero length chunk terminates the stream
+		if _, writeErr = conn.Write([]byte{0, 0, 0, 0}); writeErr != nil {
+			writeErr = errors.Wrapf(writeErr, "cannot terminate stream to %s", cc)
+		}
+	}
+	reply, err := readClamdReply(conn)
+	if err != nil {
+		if writeErr != nil {
+			return nil, errors.Wrapf(writeErr, "cannot send data to %s", cc)
+		}
+		return nil, err
+	}
+	return parseClamAVOutput(reply), nil
+}
@@ -33,6 +33,9 @@ const (
 	NameJSON      = "json"
 	NameClamav    = "clamav"
 	NameNSRL      = "nsrl"
+	NameContainer = "container"
+	NameJHOVE     = "jhove"
+	NameVeraPDF   = "verapdf"
 )
 
 // ConfigClamAV represents the configuration for ClamAV antivirus scanning.
@@ -41,10 +44,81 @@ type ConfigClamAV struct {
 	Enabled bool `toml:"enabled"`
 	// Timeout specifies the maximum duration for a scan.
 	Timeout config.Duration `toml:"timeout"`
-	// ClamScan is the path to the clamscan executable.
+	// ClamScan is the path to the clamscan or clamdscan executable.
 	ClamScan string `toml:"clamscan"`
 	// Wsl indicates whether to run clamscan via Windows Subsystem for Linux.
 	Wsl bool `toml:"wsl"`
+	// Address is the socket of a running clamd (e.g. "unix:///run/clamav/clamd.ctl" or "tcp://localhost:3310").
+	// If set, data is streamed to clamd with INSTREAM instead of calling ClamScan.
+	Address string `toml:"address"`
+	// StreamMaxLength is the StreamMaxLength setting of clamd in bytes (default 25MB).
+	StreamMaxLength int64 `toml:"streammaxlength"`
+}
+
+// ConfigJHOVE represents the configuration for the format validation with JHOVE.
+type ConfigJHOVE struct {
+	// Enabled indicates whether files are validated.
+	Enabled bool `toml:"enabled"`
+	// JHOVE is the path to the jhove executable.
+	JHOVE string `toml:"jhove"`
+	// Config is the path to a jhove.conf, empty for the default configuration of the installation.
+	Config string `toml:"config"`
+	// Wsl indicates whether to run jhove via Windows Subsystem for Linux.
+	Wsl bool `toml:"wsl"`
+	// Timeout specifies the maximum duration of a validation.
+	Timeout config.Duration `toml:"timeout"`
+}
+
+// ConfigVeraPDF represents the configuration for the PDF/A and PDF/UA conformance check with veraPDF.
+type ConfigVeraPDF struct {
+	// VeraPDF is the path to the verapdf executable.
+	VeraPDF string `toml:"verapdf"`
+	// Flavour is the validation profile (e.g. "1b", "2u", "ua1"). Empty means detection from the XMP metadata.
+	Flavour string `toml:"flavour"`
+	// Wsl indicates whether to run verapdf via Windows Subsystem for Linux.
+	Wsl bool `toml:"wsl"`
+	// Timeout specifies the maximum duration of a validation.
+	Timeout config.Duration `toml:"timeout"`
+	// Enabled indicates whether PDFs are validated.
+	Enabled bool `toml:"enabled"`
+}
+
+// ConfigContainer represents the configuration for indexing the members of ZIP, TAR and ISO containers.
+// 7z archives are not supported.
+type ConfigContainer struct {
+	// Enabled indicates whether containers are opened.
+	Enabled bool `toml:"enabled"`
+	// Actions is the list of actions used for the members. Empty means all stream actions.
+	Actions []string `toml:"actions"`
+	// MaxDepth is the number of nested container levels, which are opened (default 3).
+	MaxDepth int `toml:"maxdepth"`
+	// MaxMembers is the max. number of members of a container including all nested containers (default 10000).
+	MaxMembers int `toml:"maxmembers"`
+	// MaxSize is the max. expanded size of all members in bytes (default 4GB).
+	// ZIP and ISO containers larger than MaxSize are not spooled.
+	MaxSize int64 `toml:"maxsize"`
+}
+
+// ConfigCache represents the configuration of the result cache.
+// The signature version of ClamAV is checked for every lookup, results are not cached, if it is not available.
+type ConfigCache struct {
+	// Enabled indicates whether results are cached.
+	Enabled bool `toml:"enabled"`
+	// Badger is the folder of a badger database for the results.
+	Badger string `toml:"badger"`
+	// Dir is a folder for json result files, if no badger database is configured.
+	Dir string `toml:"dir"`
+	// TTL is the lifetime of badger entries. Zero means no expiry.
+	TTL config.Duration `toml:"ttl"`
+}
+
+// ConfigMetrics represents the configuration of the prometheus metrics.
+type ConfigMetrics struct {
+	// Enabled indicates whether the actions are instrumented.
+	Enabled bool `toml:"enabled"`
+	// Addr is the listen address of an unauthenticated metrics endpoint for the batch commands (e.g. "localhost:9100").
+	// The server exposes /metrics itself behind its jwt authentication.
+	Addr string `toml:"addr"`
 }
 
 // TypeSubtype represents a media type and its corresponding subtype.
@@ -209,8 +283,49 @@ type ConfigExternalAction struct {
 	Mimetype string `toml:"mimetype"`
 	// ActionCapabilities is a list of capabilities this action supports.
 	ActionCapabilities []ActionCapability `toml:"actioncapabilities"`
-	// CallType specifies how the external action is called (e.g., GET, POST).
+	// CallType specifies how the external action is called.
+	// EACTURL: GET request, [[PATH]] in Address is replaced by the path of the file.
+	// EACTSTREAMPOST: POST request with the data as body, [[FILENAME]] in Address is replaced by the filename.
 	CallType ExternalActionCalltype `toml:"calltype"`
+	// Mapping assigns result fields (mimetype, mimetypes, pronom, pronoms, width, height, duration, errors)
+	// to dot separated paths within the JSON response.
+	Mapping map[string]string `toml:"mapping"`
+	// Timeout is the maximum duration of the request.
+	Timeout config.Duration `toml:"timeout"`
+	// VersionAddress is an optional URL, which returns the version of the service. It is part of the cache fingerprint.
+	VersionAddress string `toml:"versionaddress"`
+}
+
+// ConfigCommandAction represents a program called as action, defined by configuration only.
+type ConfigCommandAction struct {
+	// Name is the name of the action.
+	Name string `toml:"name"`
+	// Command is the path to the executable.
+	Command string `toml:"command"`
+	// Args are the command line arguments. [[PATH]] is replaced by the path of the file, [[FILENAME]] by its base name.
+	Args []string `toml:"args"`
+	// Wsl indicates whether the command is executed via Windows Subsystem for Linux.
+	Wsl bool `toml:"wsl"`
+	// Input is "stdin" to send the data to stdin or "file" to use a (temporary) file as [[PATH]].
+	Input string `toml:"input"`
+	// Output is the format of stdout: "json", "keyvalue" or "regexp".
+	Output string `toml:"output"`
+	// Separator separates key and value for output "keyvalue" (default "=").
+	Separator string `toml:"separator"`
+	// Regexp with named groups for output "regexp".
+	Regexp string `toml:"regexp"`
+	// Mapping assigns result fields (mimetype, mimetypes, pronom, pronoms, width, height, duration, errors)
+	// to dot separated paths within the parsed output.
+	Mapping map[string]string `toml:"mapping"`
+	// Mimetype is a regular expression for the MIME types this action handles.
+	Mimetype string `toml:"mimetype"`
+	// Weight determines the order of the actions.
+	Weight uint `toml:"weight"`
+	// Timeout is the maximum execution time.
+	Timeout config.Duration `toml:"timeout"`
+	// VersionArgs are the arguments to print the version, which is part of the cache fingerprint.
+	// Without VersionArgs, size and modification time of the executable are used.
+	VersionArgs []string `toml:"versionargs"`
 }
 
 // ConfigFileMap represents a mapping from a virtual path (alias) to a local folder.
@@ -236,7 +351,11 @@ type ConfigNSRL struct {
 	// Enabled indicates whether NSRL lookup is active.
 	Enabled bool `toml:"enabled"`
 	// Badger is the path to the Badger database containing NSRL data.
+	// The database is created by nsrl2badger with SHA-1 as key ("-checksum SHA-1").
 	Badger string `toml:"badger"`
+	// Filter is the path to a bloom filter of the NSRL checksums, created by nsrl2badger ("-filter").
+	// With Badger, the database is only read on a filter hit. Without Badger, a hit only marks the file as probably known.
+	Filter string `toml:"filter"`
 }
 
 // ConfigMimeWeight represents a weight assigned to certain MIME types for relevance ranking.
@@ -251,7 +370,7 @@ type ConfigMimeWeight struct {
 type IndexerConfig struct {
 	// Enabled indicates whether the indexer is globally active.
 	Enabled bool `toml:"enabled"`
-	// LocalCache indicates whether to use a local cache for files.
+	// LocalCache indicates whether streams are spooled to TempDir for actions, which need the complete file.
 	LocalCache bool `toml:"localcache"`
 	// TempDir is the directory for temporary files.
 	TempDir string `toml:"tempdir"`
@@ -261,9 +380,12 @@ type IndexerConfig struct {
 	HeaderTimeout config.Duration `toml:"headertimeout"`
 	// HeaderSize is the number of bytes to read from the beginning of a file for identification.
 	HeaderSize int64 `toml:"headersize"`
+	// TailSize is the number of bytes from the end of a file, which are added to the header in head only mode.
+	TailSize int64 `toml:"tailsize"`
 	// DownloadMime is a MIME type that forces a download for analysis.
 	DownloadMime string `toml:"forcedownload"`
-	// MaxDownloadSize is the maximum allowed size for downloads.
+	// MaxDownloadSize is the maximum allowed size for downloads and for spooling streams to the local cache
+	// or for the identification stage. Larger streams are indexed in one stage.
 	MaxDownloadSize int64 `toml:"maxdownloadsize"`
 	// Siegfried is the configuration for Siegfried identification.
 	Siegfried ConfigSiegfried `toml:"siegfried"`
@@ -281,14 +403,27 @@ type IndexerConfig struct {
 	JSON ConfigJSON `toml:"json"`
 	// External is a list of configurations for external actions.
 	External []ConfigExternalAction `toml:"external"`
-	// FileMap is a list of virtual-to-local path mappings.
+	// Command is a list of configurations for command actions.
+	Command []ConfigCommandAction `toml:"command"`
+	// FileMap is a list of virtual-to-local path mappings. File urls need the alias as host ("file://<alias>/<path>").
 	FileMap []ConfigFileMap `toml:"filemap"`
-	// URLRegexp is a list of regular expressions for identifying relevant URLs.
+	// URLRegexp is a list of regular expressions for the allowed http(s) and sftp URLs.
+	// Without URLRegexp, no http(s) or sftp URL is allowed.
 	URLRegexp []string `toml:"urlregexp"`
 	// NSRL is the configuration for NSRL lookups.
 	NSRL ConfigNSRL `toml:"nsrl"`
 	// Clamav is the configuration for ClamAV antivirus scanning.
 	Clamav ConfigClamAV `toml:"clamav"`
+	// JHOVE is the configuration for the format validation with JHOVE.
+	JHOVE ConfigJHOVE `toml:"jhove"`
+	// VeraPDF is the configuration for the PDF/A and PDF/UA conformance check with veraPDF.
+	VeraPDF ConfigVeraPDF `toml:"verapdf"`
+	// Container is the configuration for indexing the members of containers.
+	Container ConfigContainer `toml:"container"`
+	// Cache is the configuration of the result cache.
+	Cache ConfigCache `toml:"cache"`
+	// Metrics is the configuration of the prometheus metrics.
+	Metrics ConfigMetrics `toml:"metrics"`
 	// MimeRelevance is a map of MIME type relevance weights.
 	MimeRelevance map[string]ConfigMimeWeight `toml:"mimerelevance"`
 }
@@ -1,6 +1,17 @@
 package indexer
 
 import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io/fs"
+	"net"
+	"net/http"
+	"os/exec"
+	"slices"
+	"sync"
+
+	"emperror.dev/errors"
 	"github.com/je4/utils/v2/pkg/zLogger"
 	archiveerror "github.com/ocfl-archive/error/pkg/error"
 	"github.com/ocfl-archive/indexer/v3/internal"
@@ -11,18 +22,122 @@ var ErrorFactory = archiveerror.NewFactory("INDEXER")
 type errorID = archiveerror.ID
 
 const (
-	IndexerInit = "IndexerInit"
+	IndexerInit               = "IndexerInit"
+	IndexerToolNotFound       = "IndexerToolNotFound"
+	IndexerTimeout            = "IndexerTimeout"
+	IndexerExitCode           = "IndexerExitCode"
+	IndexerOutputParse        = "IndexerOutputParse"
+	IndexerUnsupportedFormat  = "IndexerUnsupportedFormat"
+	IndexerServiceUnavailable = "IndexerServiceUnavailable"
+)
+
+var (
+	ErrUnsupportedFormat  = errors.New("format not supported")
+	ErrServiceUnavailable = errors.New("service unavailable")
+)
+
+// retriableErrors may succeed, if the action is called again later
+var retriableErrors = []errorID{IndexerTimeout, IndexerServiceUnavailable}
+
+var (
+	registerOnce sync.Once
+	registerErr  error
 )
 
+// registerErrors loads the errors of errors.toml into the ErrorFactory once
+func registerErrors() error {
+	registerOnce.Do(func() {
+		const errorsEmbedToml string = "errors.toml"
+		archiveErrs, err := archiveerror.LoadTOMLFileFS(internal.InternalFS, errorsEmbedToml)
+		if err != nil {
+			registerErr = err
+			return
+		}
+		registerErr = ErrorFactory.RegisterErrors(archiveErrs)
+	})
+	return registerErr
+}
+
 func configErrorFactory(logger zLogger.ZLogger) {
-	var err error
-	const errorsEmbedToml string = "errors.toml"
-	archiveErrs, err := archiveerror.LoadTOMLFileFS(internal.InternalFS, errorsEmbedToml)
-	if err != nil {
+	if err := registerErrors(); err != nil {
 		logger.Fatal().Err(err).Msg("cannot load error config file")
 	}
+}
 
-	if err := ErrorFactory.RegisterErrors(archiveErrs); err != nil {
-		logger.Fatal().Err(err).Msg("cannot load error config file")
+// ActionError is the structured error of a failed action
+type ActionError struct {
+	ID     errorID `json:"id"`
+	Action string  `json:"action"`
+	// Message is the error message of the action
+	Message string `json:"message"`
+	// Severity is the weight of the error in errors.toml
+	Severity  int64 `json:"severity"`
+	Retriable bool  `json:"retriable"`
+}
+
+// NewActionError creates the structured error of an action with the severity of the registered error
+func NewActionError(id errorID, action, message string) *ActionError {
+	_ = registerErrors()
+	return &ActionError{
+		ID:        id,
+		Action:    action,
+		Message:   message,
+		Severity:  ErrorFactory.NewError(id, "", nil).Weight,
+		Retriable: slices.Contains(retriableErrors, id),
+	}
+}
+
+// errorIDOf classifies the error of an action
+func errorIDOf(err error) errorID {
+	var archiveErr *archiveerror.Error
+	var execErr *exec.Error
+	var pathErr *fs.PathError
+	var exitErr *exec.ExitError
+	var jsonSyntaxErr *json.SyntaxError
+	var jsonTypeErr *json.UnmarshalTypeError
+	var xmlSyntaxErr *xml.SyntaxError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &archiveErr):
+		return archiveErr.ID
+	case errors.Is(err, context.DeadlineExceeded):
+		return IndexerTimeout
+	case errors.As(err, &execErr), errors.As(err, &pathErr) && pathErr.Op == "fork/exec":
+		return IndexerToolNotFound
+	case errors.As(err, &exitErr):
+		return IndexerExitCode
+	case errors.As(err, &jsonSyntaxErr), errors.As(err, &jsonTypeErr), errors.As(err, &xmlSyntaxErr):
+		return IndexerOutputParse
+	case errors.Is(err, ErrUnsupportedFormat):
+		return IndexerUnsupportedFormat
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return IndexerTimeout
+		}
+		return IndexerServiceUnavailable
+	case errors.Is(err, ErrServiceUnavailable):
+		return IndexerServiceUnavailable
+	default:
+		return archiveerror.IDUnknownError
+	}
+}
+
+// commandError adds the context error, if the command has been killed because of the context
+func commandError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return errors.WithMessage(ctxErr, err.Error())
+	}
+	return err
+}
+
+// httpStatusError returns the error of a remote service response status
+func httpStatusError(status int) error {
+	switch status {
+	case http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity:
+		return ErrUnsupportedFormat
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return ErrServiceUnavailable
+	default:
+		return errors.Errorf("status %d", status)
 	}
 }
@@ -17,9 +17,7 @@ import (
 	"emperror.dev/errors"
 	"fmt"
 	"net/url"
-	"os"
 	"path/filepath"
-	"runtime"
 	"strings"
 )
 
@@ -31,27 +29,29 @@ func NewFileMapper(mapping map[string]string) *FileMapper {
 	return &FileMapper{mapping: mapping}
 }
 
+// Get returns the local filename of a file url. The host of the url is the alias of a mapped folder,
+// the path must stay within this folder.
 func (fm *FileMapper) Get(uri *url.URL) (string, error) {
 	if uri.Scheme != "file" {
 
 		return "", errors.New(fmt.Sprintf("cannot handle scheme %s: need file scheme", uri.Scheme))
 	}
-	var filename string
-	var ok bool
-	if uri.Host != "" {
-		filename, ok = fm.mapping[strings.ToLower(uri.Host)]
-		if !ok {
-			return "", errors.New(fmt.Sprintf("no mapping for %s", uri.Host))
-		}
+	if uri.Host == "" {
+		return "", errors.WithMessagef(ErrURLNotAllowed, "no alias in %s", uri)
+	}
+	folder, ok := fm.mapping[strings.ToLower(uri.Host)]
+	if !ok {
+		return "", errors.WithMessagef(ErrURLNotAllowed, "no mapping for %s", uri.Host)
 	}
 	p, err := url.QueryUnescape(uri.EscapedPath())
 	if err != nil {
 		return "", errors.Wrapf(err, "cannot unescape %s", uri.EscapedPath())
 	}
-	filename = filepath.Join(filename, p)
-	filename = filepath.Clean(filename)
-	if runtime.GOOS == "windows" {
-		filename = strings.TrimPrefix(filename, string(os.PathSeparator))
+	folder = filepath.Clean(folder)
+	filename := filepath.Join(folder, p)
+	rel, err := filepath.Rel(folder, filename)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.WithMessagef(ErrURLNotAllowed, "%s is outside of %s", uri, uri.Host)
 	}
 	return filename, nil
 }
@@ -0,0 +1,239 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrURLNotAllowed is returned for urls, which are not supported or not allowed by the configuration
+var ErrURLNotAllowed = errors.New("url not allowed")
+
+// SetSources configures the sources of IndexURL. Http(s) and sftp urls must match one of urlRegexp,
+// without urlRegexp they are not allowed.
+// Content with a mimetype matching downloadMime is always downloaded.
+func (ad *ActionDispatcher) SetSources(fm *FileMapper, sftp *SFTP, urlRegexp []*regexp.Regexp, downloadMime *regexp.Regexp) {
+	ad.fileMapper = fm
+	ad.sftp = sftp
+	ad.urlRegexp = urlRegexp
+	ad.downloadMime = downloadMime
+	ad.httpClient = newHTTPClient(ad.headerTimeout)
+}
+
+// newHTTPClient creates a client with the proxy and dial settings of the default transport,
+// which waits at most headerTimeout (if > 0) for the response header
+func newHTTPClient(headerTimeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = headerTimeout
+	return &http.Client{Transport: transport}
+}
+
+// IndexURL indexes file, sftp and http(s) urls. File urls are resolved with the file mapper.
+// Http(s) content is downloaded, if it is not larger than the max. download size. Content with a mimetype,
+// which has to be downloaded, is downloaded without size limit. Otherwise the head actions get the header
+// of the content and the actions, which can deal with http(s), get the url. The result is marked as partial then.
+func (ad *ActionDispatcher) IndexURL(ctx context.Context, uri *url.URL, actions []string) (result *ResultV2, err error) {
+	stateFiles := []string{path.Base(uri.Path)}
+	ctx, span := ad.startSpan(ctx, "IndexURL", stateFiles, actions)
+	span.SetAttributes(attribute.String("url.scheme", uri.Scheme))
+	defer func() { endSpan(span, result, err) }()
+	switch uri.Scheme {
+	case "file":
+		if ad.fileMapper == nil {
+			return nil, errors.WithMessage(ErrURLNotAllowed, "file mapper not configured")
+		}
+		filename, err := ad.fileMapper.Get(uri)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot map %s", uri)
+		}
+		return ad.DoV2Context(ctx, filename, stateFiles, actions)
+	case "sftp":
+		if ad.sftp == nil {
+			return nil, errors.WithMessage(ErrURLNotAllowed, "sftp not configured")
+		}
+		if err := ad.urlAllowed(uri); err != nil {
+			return nil, err
+		}
+		pr, pw := io.Pipe()
+		go func() {
+			_, err := ad.sftp.Get(*uri, pw)
+			pw.CloseWithError(err)
+		}()
+		// unblock the sftp transfer, if not all data has been read
+		defer pr.Close()
+		return ad.StreamContext(ctx, ad.downloadLimit(pr, uri), stateFiles, actions)
+	case "http", "https":
+		return ad.indexHTTP(ctx, uri, stateFiles, actions)
+	default:
+		return nil, errors.WithMessagef(ErrURLNotAllowed, "scheme '%s' not supported", uri.Scheme)
+	}
+}
+
+// urlAllowed checks uri against the allowed url regexps
+func (ad *ActionDispatcher) urlAllowed(uri *url.URL) error {
+	if !slices.ContainsFunc(ad.urlRegexp, func(re *regexp.Regexp) bool { return re.MatchString(uri.String()) }) {
+		return errors.WithMessagef(ErrURLNotAllowed, "%s", uri)
+	}
+	return nil
+}
+
+func (ad *ActionDispatcher) indexHTTP(ctx context.Context, uri *url.URL, stateFiles []string, actions []string) (*ResultV2, error) {
+	if err := ad.urlAllowed(uri); err != nil {
+		return nil, err
+	}
+	headerSize := ad.headerSize
+	if headerSize <= 0 {
+		headerSize = defaultHeaderSize
+	}
+	// the header decides, whether the content is downloaded
+	resp, err := ad.httpGet(ctx, uri, fmt.Sprintf("bytes=0-%d", headerSize-1))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	size := httpContentSize(resp)
+	contentType, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";")
+	forced := ad.downloadMime != nil && ad.downloadMime.MatchString(strings.TrimSpace(contentType))
+	if forced || size < 0 || ad.maxSpoolSize <= 0 || size <= ad.maxSpoolSize {
+		var body io.Reader = resp.Body
+		if resp.StatusCode == http.StatusPartialContent && (size < 0 || size > headerSize) {
+			resp.Body.Close()
+			if resp, err = ad.httpGet(ctx, uri, ""); err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
+			body = resp.Body
+		}
+		if !forced {
+			body = ad.downloadLimit(body, uri)
+		}
+		return ad.StreamContext(ctx, body, stateFiles, actions)
+	}
+
+	head, err := io.ReadAll(io.LimitReader(resp.Body, headerSize))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read header of %s", uri)
+	}
+	// the reader must not be an io.ReaderAt, the tail is not available
+	result, err := ad.HeadContext(ctx, io.MultiReader(bytes.NewReader(head)), size, stateFiles, actions)
+	if err != nil {
+		return nil, err
+	}
+	result.Partial = true
+	caps := ACTHTTP
+	if uri.Scheme == "https" {
+		caps = ACTHTTPS
+	}
+	for _, name := range actions {
+		action, ok := ad.actions[name]
+		// head actions already had the header
+		if !ok || action.GetCaps()&caps == 0 || action.GetCaps()&ACTHEAD != 0 {
+			continue
+		}
+		if !action.CanHandle(result.Mimetype, uri.Path) {
+			ad.metrics.skipped(action.GetName())
+			continue
+		}
+		r, err := ad.doV2Action(ctx, action, uri.String())
+		if err != nil {
+			r = NewResultV2()
+			r.AddError(action.GetName(), err)
+		}
+		result.Merge(r)
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrapf(err, "indexing of %s aborted", uri)
+		}
+	}
+	result.Size = uint64(size)
+	return result, nil
+}
+
+// httpGet requests uri with an optional range
+func (ad *ActionDispatcher) httpGet(ctx context.Context, uri *url.URL, byteRange string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri.String(), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot create request for %s", uri)
+	}
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+	injectTrace(ctx, req)
+	client := ad.httpClient
+	if client == nil {
+		client = newHTTPClient(ad.headerTimeout)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get %s", uri)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return nil, errors.Errorf("cannot get %s: %s", uri, resp.Status)
+	}
+	return resp, nil
+}
+
+// httpContentSize returns the complete size of the content or -1, if unknown
+func httpContentSize(resp *http.Response) int64 {
+	if resp.StatusCode != http.StatusPartialContent {
+		return resp.ContentLength
+	}
+	// Content-Range: bytes 0-99/1234
+	_, total, ok := strings.Cut(resp.Header.Get("Content-Range"), "/")
+	if !ok {
+		return -1
+	}
+	size, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return size
+}
+
+// downloadLimit returns an error, if more than the max. download size is read
+func (ad *ActionDispatcher) downloadLimit(reader io.Reader, uri *url.URL) io.Reader {
+	if ad.maxSpoolSize <= 0 {
+		return reader
+	}
+	return &downloadLimitReader{r: reader, remaining: ad.maxSpoolSize, uri: uri}
+}
+
+type downloadLimitReader struct {
+	r         io.Reader
+	remaining int64
+	uri       *url.URL
+}
+
+func (lr *downloadLimitReader) Read(p []byte) (int, error) {
+	n, err := lr.r.Read(p)
+	lr.remaining -= int64(n)
+	if lr.remaining < 0 {
+		return n, errors.Errorf("%s exceeds max download size", lr.uri)
+	}
+	return n, err
+}
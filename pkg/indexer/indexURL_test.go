@@ -0,0 +1,113 @@
+package indexer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexURL(t *testing.T) {
+	data := strings.Repeat("0123456789", 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "test.txt", time.Time{}, strings.NewReader(data))
+	}))
+	defer server.Close()
+	folder := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(folder, "test.txt"), []byte(data), 0644))
+
+	tests := []struct {
+		name         string
+		url          string
+		maxSize      int64
+		downloadMime string
+		wantFull     int
+		wantHead     int
+		wantURL      bool
+		wantPartial  bool
+		wantErr      error
+	}{
+		{name: "download", url: server.URL + "/test.txt", wantFull: len(data), wantHead: len(data)},
+		{name: "too large", url: server.URL + "/test.txt", maxSize: 500, wantHead: 100, wantURL: true, wantPartial: true},
+		{name: "forced download", url: server.URL + "/test.txt", maxSize: 500, downloadMime: "^text/", wantFull: len(data), wantHead: len(data)},
+		{name: "file", url: "file://data/test.txt", wantFull: len(data), wantHead: len(data)},
+		{name: "not allowed", url: "http://localhost:1/test.txt", wantErr: ErrURLNotAllowed},
+		{name: "sftp not allowed", url: "sftp://localhost:1/test.txt", wantErr: ErrURLNotAllowed},
+		{name: "file without alias", url: "file:///etc/passwd", wantErr: ErrURLNotAllowed},
+		{name: "file unknown alias", url: "file://other/test.txt", wantErr: ErrURLNotAllowed},
+		{name: "file outside alias", url: "file://data/../../etc/shadow", wantErr: ErrURLNotAllowed},
+		{name: "file escaped outside alias", url: "file://data/..%2F..%2Fetc%2Fshadow", wantErr: ErrURLNotAllowed},
+		{name: "scheme", url: "ftp://localhost/test.txt", wantErr: ErrURLNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ad := NewActionDispatcher(nil)
+			ad.SetLocalCache(false, tt.maxSize)
+			ad.SetHead(100, 0, 0)
+			var downloadMime *regexp.Regexp
+			if tt.downloadMime != "" {
+				downloadMime = regexp.MustCompile(tt.downloadMime)
+			}
+			ad.SetSources(NewFileMapper(map[string]string{"data": folder}), &SFTP{}, []*regexp.Regexp{regexp.MustCompile("^" + regexp.QuoteMeta(server.URL))}, downloadMime)
+			head := &stageAction{name: "head", caps: ACTFILEHEAD | ACTSTREAM}
+			full := &stageAction{name: "full", caps: ACTSTREAM}
+			web := &stageAction{name: "web", caps: ACTFILEFULL | ACTWEB | ACTSTREAM}
+			ad.RegisterAction(head)
+			ad.RegisterAction(full)
+			ad.RegisterAction(web)
+
+			uri, err := url.Parse(tt.url)
+			assert.NoError(t, err)
+			result, err := ad.IndexURL(context.Background(), uri, []string{"head", "full", "web"})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantHead, head.size)
+			assert.Equal(t, tt.wantFull, full.size)
+			assert.Equal(t, tt.wantURL, web.file == tt.url)
+			assert.Equal(t, tt.wantPartial, result.Partial)
+			assert.Equal(t, uint64(len(data)), result.Size)
+		})
+	}
+
+	// without url regexps, no http(s) or sftp url is allowed
+	ad := NewActionDispatcher(nil)
+	ad.SetSources(nil, &SFTP{}, nil, nil)
+	for _, u := range []string{server.URL + "/test.txt", "sftp://localhost/test.txt"} {
+		uri, err := url.Parse(u)
+		assert.NoError(t, err)
+		_, err = ad.IndexURL(context.Background(), uri, nil)
+		assert.ErrorIs(t, err, ErrURLNotAllowed)
+	}
+}
+
+func TestSetSourcesHTTPClient(t *testing.T) {
+	ad := NewActionDispatcher(nil)
+	ad.SetSources(nil, nil, nil, nil)
+	transport, ok := ad.httpClient.Transport.(*http.Transport)
+	if !assert.True(t, ok) {
+		return
+	}
+	// the settings of the default transport are kept
+	assert.NotNil(t, transport.Proxy)
+	assert.NotNil(t, transport.DialContext)
+	assert.Zero(t, transport.ResponseHeaderTimeout)
+
+	// the header timeout can be configured afterwards
+	ad.SetHead(0, 0, time.Second)
+	transport = ad.httpClient.Transport.(*http.Transport)
+	assert.Equal(t, time.Second, transport.ResponseHeaderTimeout)
+	assert.Zero(t, http.DefaultTransport.(*http.Transport).ResponseHeaderTimeout)
+}
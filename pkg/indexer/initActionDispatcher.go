@@ -46,6 +46,9 @@ func InitActionDispatcher(fss map[string]fs.FS, conf IndexerConfig, logger zLogg
 		return nil, errors.Wrap(err, "cannot convert config string map to mime relevance")
 	}
 	actionDispatcher := NewActionDispatcher(mimeRelevance)
+	actionDispatcher.SetTempDir(conf.TempDir)
+	actionDispatcher.SetLocalCache(conf.LocalCache, conf.MaxDownloadSize)
+	actionDispatcher.SetHead(conf.HeaderSize, conf.TailSize, time.Duration(conf.HeaderTimeout))
 
 	var signatureData []byte
 	found := fsRegexp.FindStringSubmatch(conf.Siegfried.SignatureFile)
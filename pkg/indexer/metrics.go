@@ -0,0 +1,139 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package indexer
+
+import (
+	"net/http"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	MetricStatusSuccess = "success"
+	MetricStatusError   = "error"
+	MetricStatusSkipped = "skipped"
+)
+
+// Metrics collects the prometheus metrics of the action dispatcher.
+// All methods can be called on a nil Metrics.
+type Metrics struct {
+	registry  *prometheus.Registry
+	duration  *prometheus.HistogramVec
+	actions   *prometheus.CounterVec
+	inFlight  *prometheus.GaugeVec
+	bytes     prometheus.Counter
+	mimetypes *prometheus.CounterVec
+	pronoms   *prometheus.CounterVec
+}
+
+// NewMetrics registers the indexer metrics with registry
+func NewMetrics(registry *prometheus.Registry) (*Metrics, error) {
+	m := &Metrics{
+		registry: registry,
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "indexer",
+			Name:      "action_duration_seconds",
+			Help:      "Duration of the actions.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10),
+		}, []string{"action"}),
+		actions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "indexer",
+			Name:      "actions_total",
+			Help:      "Number of action calls by status (success, error, skipped).",
+		}, []string{"action", "status"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "indexer",
+			Name:      "actions_in_flight",
+			Help:      "Number of running actions.",
+		}, []string{"action"}),
+		bytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "indexer",
+			Name:      "stream_bytes_total",
+			Help:      "Number of bytes streamed to the actions.",
+		}),
+		mimetypes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "indexer",
+			Name:      "mimetypes_total",
+			Help:      "Number of indexed files by detected mimetype.",
+		}, []string{"mimetype"}),
+		pronoms: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "indexer",
+			Name:      "pronoms_total",
+			Help:      "Number of indexed files by detected pronom.",
+		}, []string{"pronom"}),
+	}
+	for _, c := range []prometheus.Collector{m.duration, m.actions, m.inFlight, m.bytes, m.mimetypes, m.pronoms} {
+		if err := registry.Register(c); err != nil {
+			return nil, errors.Wrap(err, "cannot register indexer metrics")
+		}
+	}
+	return m, nil
+}
+
+// Handler exposes the metrics of the registry
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// start counts a running action and returns the function, which records the duration and the status
+func (m *Metrics) start(action string) func(status string) {
+	if m == nil {
+		return func(string) {}
+	}
+	m.inFlight.WithLabelValues(action).Inc()
+	start := time.Now()
+	return func(status string) {
+		m.inFlight.WithLabelValues(action).Dec()
+		m.duration.WithLabelValues(action).Observe(time.Since(start).Seconds())
+		m.actions.WithLabelValues(action, status).Inc()
+	}
+}
+
+func (m *Metrics) skipped(action string) {
+	if m == nil {
+		return
+	}
+	m.actions.WithLabelValues(action, MetricStatusSkipped).Inc()
+}
+
+func (m *Metrics) streamed(n int64) {
+	if m == nil || n <= 0 {
+		return
+	}
+	m.bytes.Add(float64(n))
+}
+
+// identified counts the mimetype and pronom of a result
+func (m *Metrics) identified(result *ResultV2) {
+	if m == nil || result == nil {
+		return
+	}
+	if result.Mimetype != "" {
+		m.mimetypes.WithLabelValues(result.Mimetype).Inc()
+	}
+	if result.Pronom != "" {
+		m.pronoms.WithLabelValues(result.Pronom).Inc()
+	}
+}
+
+// actionStatus returns the metric status of an action call
+func actionStatus(action string, result *ResultV2, err error) string {
+	if err != nil || (result != nil && result.Errors[action] != "") {
+		return MetricStatusError
+	}
+	return MetricStatusSuccess
+}
@@ -0,0 +1,40 @@
+package indexer
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics, err := NewMetrics(prometheus.NewRegistry())
+	if !assert.NoError(t, err) {
+		return
+	}
+	ad := NewActionDispatcher(nil)
+	ad.SetMetrics(metrics)
+	ad.RegisterAction(&stageAction{name: "ident", caps: ACTSTREAM | ACTIDENT, mimetype: "video/x-test"})
+	ad.RegisterAction(&stageAction{name: "other", caps: ACTSTREAM, handle: "audio/x-test"})
+	data := strings.Repeat("plain text ", 100)
+	_, err = ad.Stream(strings.NewReader(data), []string{"test.bin"}, []string{"ident", "other"})
+	assert.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(resp, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	for _, line := range []string{
+		`indexer_actions_total{action="ident",status="success"} 1`,
+		`indexer_actions_total{action="other",status="skipped"} 1`,
+		`indexer_actions_in_flight{action="ident"} 0`,
+		`indexer_action_duration_seconds_count{action="ident"} 1`,
+		`indexer_stream_bytes_total 1100`,
+		`indexer_mimetypes_total{mimetype="video/x-test"} 1`,
+	} {
+		assert.Contains(t, string(body), line)
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package indexer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+)
+
+const (
+	METSNamespace      = "http://www.loc.gov/METS/"
+	METSSchemaLocation = "http://www.loc.gov/METS/ https://www.loc.gov/standards/mets/mets.xsd " + PREMISSchemaLocation
+	XLinkNamespace     = "http://www.w3.org/1999/xlink"
+)
+
+// metsChecksumAlgorithms are the checksums used in the file section, in order of preference
+var metsChecksumAlgorithms = []string{"sha512", "sha384", "sha256", "sha1", "md5"}
+
+// METS is a METS document with one techMD per file, which contains the PREMIS object of the indexer result
+type METS struct {
+	XMLName        xml.Name      `xml:"mets:mets"`
+	XMLNSMETS      string        `xml:"xmlns:mets,attr"`
+	XMLNSPremis    string        `xml:"xmlns:premis,attr"`
+	XMLNSXLink     string        `xml:"xmlns:xlink,attr"`
+	XMLNSXSI       string        `xml:"xmlns:xsi,attr"`
+	SchemaLocation string        `xml:"xsi:schemaLocation,attr,omitempty"`
+	Label          string        `xml:"LABEL,attr,omitempty"`
+	Header         METSHeader    `xml:"mets:metsHdr"`
+	AmdSec         *METSAmdSec   `xml:"mets:amdSec,omitempty"`
+	FileSec        METSFileSec   `xml:"mets:fileSec"`
+	StructMap      METSStructMap `xml:"mets:structMap"`
+}
+
+type METSHeader struct {
+	CreateDate string      `xml:"CREATEDATE,attr"`
+	Agents     []METSAgent `xml:"mets:agent"`
+}
+
+type METSAgent struct {
+	Role      string `xml:"ROLE,attr"`
+	Type      string `xml:"TYPE,attr"`
+	OtherType string `xml:"OTHERTYPE,attr,omitempty"`
+	Name      string `xml:"mets:name"`
+}
+
+type METSAmdSec struct {
+	ID      string      `xml:"ID,attr"`
+	TechMDs []METSMDSec `xml:"mets:techMD"`
+}
+
+type METSMDSec struct {
+	ID     string     `xml:"ID,attr"`
+	MDWrap METSMDWrap `xml:"mets:mdWrap"`
+}
+
+type METSMDWrap struct {
+	MDType  string      `xml:"MDTYPE,attr"`
+	XMLData METSXMLData `xml:"mets:xmlData"`
+}
+
+type METSXMLData struct {
+	Object *PREMISObject `xml:"premis:object"`
+}
+
+type METSFileSec struct {
+	Groups []METSFileGrp `xml:"mets:fileGrp"`
+}
+
+type METSFileGrp struct {
+	Use   string     `xml:"USE,attr,omitempty"`
+	Files []METSFile `xml:"mets:file"`
+}
+
+type METSFile struct {
+	ID           string     `xml:"ID,attr"`
+	MimeType     string     `xml:"MIMETYPE,attr,omitempty"`
+	Size         uint64     `xml:"SIZE,attr,omitempty"`
+	Checksum     string     `xml:"CHECKSUM,attr,omitempty"`
+	ChecksumType string     `xml:"CHECKSUMTYPE,attr,omitempty"`
+	AdmID        string     `xml:"ADMID,attr,omitempty"`
+	FLocat       METSFLocat `xml:"mets:FLocat"`
+}
+
+type METSFLocat struct {
+	LocType string `xml:"LOCTYPE,attr"`
+	Href    string `xml:"xlink:href,attr"`
+}
+
+type METSStructMap struct {
+	Type string  `xml:"TYPE,attr,omitempty"`
+	Div  METSDiv `xml:"mets:div"`
+}
+
+type METSDiv struct {
+	Type  string     `xml:"TYPE,attr,omitempty"`
+	Label string     `xml:"LABEL,attr,omitempty"`
+	Fptrs []METSFptr `xml:"mets:fptr"`
+	Divs  []METSDiv  `xml:"mets:div"`
+}
+
+type METSFptr struct {
+	FileID string `xml:"FILEID,attr"`
+}
+
+// NewMETS creates a METS document for the results of a batch. The files are sorted by path and referenced
+// relative to the batch folder. Every file with a result gets a techMD with its PREMIS object,
+// files without result are only listed in the file section.
+func NewMETS(label string, results []*BatchResult) (*METS, error) {
+	results = slices.Clone(results)
+	slices.SortStableFunc(results, func(a, b *BatchResult) int { return strings.Compare(a.Path, b.Path) })
+
+	mets := &METS{
+		XMLNSMETS:      METSNamespace,
+		XMLNSPremis:    PREMISNamespace,
+		XMLNSXLink:     XLinkNamespace,
+		XMLNSXSI:       "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: METSSchemaLocation,
+		Label:          label,
+		Header: METSHeader{
+			CreateDate: time.Now().UTC().Format(time.RFC3339),
+			Agents:     []METSAgent{{Role: "CREATOR", Type: "OTHER", OtherType: "SOFTWARE", Name: "indexer"}},
+		},
+		StructMap: METSStructMap{Type: "PHYSICAL", Div: METSDiv{Type: "folder", Label: label}},
+	}
+	amdSec := &METSAmdSec{ID: "AMD_1"}
+	fileGrp := METSFileGrp{Use: "ORIGINAL"}
+	for i, br := range results {
+		file := METSFile{
+			ID:     fmt.Sprintf("FILE_%d", i+1),
+			FLocat: METSFLocat{LocType: "URL", Href: (&url.URL{Path: br.Path}).String()},
+		}
+		if br.Result != nil {
+			object, err := NewPREMISObject(br.Result, br.Path, br.Path)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "cannot create premis object of %s", br.Path)
+			}
+			techMD := METSMDSec{
+				ID:     fmt.Sprintf("TECH_%d", i+1),
+				MDWrap: METSMDWrap{MDType: "PREMIS:OBJECT", XMLData: METSXMLData{Object: object}},
+			}
+			amdSec.TechMDs = append(amdSec.TechMDs, techMD)
+			file.AdmID = techMD.ID
+			file.MimeType = br.Result.Mimetype
+			file.Size = br.Result.Size
+			for _, alg := range metsChecksumAlgorithms {
+				if checksum, ok := br.Result.Checksum[alg]; ok {
+					file.Checksum = checksum
+					file.ChecksumType = digestAlgorithm(alg)
+					break
+				}
+			}
+		}
+		fileGrp.Files = append(fileGrp.Files, file)
+		mets.StructMap.Div.Divs = append(mets.StructMap.Div.Divs, METSDiv{
+			Type:  "file",
+			Label: br.Path,
+			Fptrs: []METSFptr{{FileID: file.ID}},
+		})
+	}
+	if len(amdSec.TechMDs) > 0 {
+		mets.AmdSec = amdSec
+	}
+	mets.FileSec.Groups = []METSFileGrp{fileGrp}
+	return mets, nil
+}
+
+// Write writes the METS xml document to w
+func (m *METS) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return errors.Wrap(err, "cannot write xml header")
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		return errors.Wrap(err, "cannot encode mets")
+	}
+	return errors.WithStack(enc.Close())
+}
@@ -0,0 +1,83 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testMETS writes the METS document of a batch with a video, an image and a failed file
+func testMETS(t *testing.T) []byte {
+	t.Helper()
+	video := NewResultV2()
+	video.Mimetype = "video/mp4"
+	video.Size = 1234
+	video.Checksum = map[string]string{"sha512": "abc", "md5": "def"}
+	video.Metadata = map[string]any{
+		NameFFProbe: map[string]any{"format": map[string]any{"duration": "1.5"}},
+	}
+	image := NewResultV2()
+	image.Mimetype = "image/png"
+	image.Size = 42
+	image.Checksum = map[string]string{"md5": "ghi"}
+
+	results := []*BatchResult{
+		{Path: "video/test file.mp4", Result: video},
+		{Path: "broken.tif", Error: "cannot open"},
+		{Path: "image.png", Result: image},
+	}
+	mets, err := NewMETS("batch", results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := mets.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestMETS(t *testing.T) {
+	data := testMETS(t)
+	var doc premisElement
+	if !assert.NoError(t, xml.Unmarshal(data, &doc)) {
+		return
+	}
+	assert.Equal(t, xml.Name{Space: METSNamespace, Local: "mets"}, doc.XMLName)
+	assert.Equal(t, []string{"metsHdr", "amdSec", "fileSec", "structMap"}, doc.childNames())
+
+	amdSec := doc.child("amdSec")
+	assert.Equal(t, []string{"techMD", "techMD"}, amdSec.childNames())
+	object := amdSec.Children[1].child("mdWrap").child("xmlData").child("object")
+	if !assert.NotNil(t, object) {
+		return
+	}
+	assert.Equal(t, PREMISNamespace, object.XMLName.Space)
+	extension := object.child("objectCharacteristics").child("objectCharacteristicsExtension").child("metadata")
+	assert.JSONEq(t, `{"format": {"duration": "1.5"}}`, extension.Text)
+
+	// the files are sorted by path
+	fileGrp := doc.child("fileSec").child("fileGrp")
+	assert.Equal(t, []string{"file", "file", "file"}, fileGrp.childNames())
+	attrs := func(e premisElement) map[string]string {
+		m := map[string]string{}
+		for _, attr := range e.Attrs {
+			m[attr.Name.Local] = attr.Value
+		}
+		return m
+	}
+	assert.Equal(t, map[string]string{"ID": "FILE_1"}, attrs(fileGrp.Children[0]))
+	assert.Equal(t, map[string]string{"ID": "FILE_2", "MIMETYPE": "image/png", "SIZE": "42", "CHECKSUM": "ghi", "CHECKSUMTYPE": "MD5", "ADMID": "TECH_2"}, attrs(fileGrp.Children[1]))
+	videoFile := attrs(fileGrp.Children[2])
+	assert.Equal(t, "SHA-512", videoFile["CHECKSUMTYPE"])
+	assert.Equal(t, "TECH_3", videoFile["ADMID"])
+	assert.Equal(t, "video/test%20file.mp4", attrs(*fileGrp.Children[2].child("FLocat"))["href"])
+
+	div := doc.child("structMap").child("div")
+	assert.Equal(t, []string{"div", "div", "div"}, div.childNames())
+	assert.Equal(t, "FILE_3", attrs(*div.Children[2].child("fptr"))["FILEID"])
+	assert.True(t, strings.HasPrefix(string(data), xml.Header))
+}
@@ -0,0 +1,143 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package indexer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+
+	"emperror.dev/errors"
+)
+
+const nsrlFilterMagic = "NSRLBF01"
+
+// NSRLFilter is a bloom filter over the keys of the NSRL file entries.
+// Contains never returns false for an added key.
+type NSRLFilter struct {
+	bits  []uint64
+	m     uint64
+	k     uint32
+	count uint64
+}
+
+// NewNSRLFilter creates an empty filter for n keys with false positive rate fp
+func NewNSRLFilter(n uint64, fp float64) *NSRLFilter {
+	if n == 0 {
+		n = 1
+	}
+	if fp <= 0 || fp >= 1 {
+		fp = 0.001
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(fp) / (math.Ln2 * math.Ln2)))
+	// round up to full words
+	m = (m + 63) / 64 * 64
+	k := uint32(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &NSRLFilter{bits: make([]uint64, m/64), m: m, k: k}
+}
+
+// hashes uses double hashing with two fnv variants
+func (f *NSRLFilter) hashes(key string) (uint64, uint64) {
+	h1 := fnv.New64a()
+	h1.Write([]byte(key))
+	h2 := fnv.New64()
+	h2.Write([]byte(key))
+	return h1.Sum64(), h2.Sum64() | 1
+}
+
+func (f *NSRLFilter) Add(key string) {
+	h1, h2 := f.hashes(key)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		pos := (h1 + i*h2) % f.m
+		f.bits[pos/64] |= 1 << (pos % 64)
+	}
+	f.count++
+}
+
+func (f *NSRLFilter) Contains(key string) bool {
+	h1, h2 := f.hashes(key)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		pos := (h1 + i*h2) % f.m
+		if f.bits[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Count returns the number of added keys
+func (f *NSRLFilter) Count() uint64 {
+	return f.count
+}
+
+// WriteTo writes the filter as magic, m, k, count and the bit words in little endian
+func (f *NSRLFilter) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(nsrlFilterMagic); err != nil {
+		return 0, errors.Wrap(err, "cannot write filter header")
+	}
+	for _, v := range []any{f.m, f.k, f.count, f.bits} {
+		if err := binary.Write(bw, binary.LittleEndian, v); err != nil {
+			return 0, errors.Wrap(err, "cannot write filter")
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, errors.Wrap(err, "cannot write filter")
+	}
+	return int64(len(nsrlFilterMagic) + 8 + 4 + 8 + len(f.bits)*8), nil
+}
+
+func ReadNSRLFilter(r io.Reader) (*NSRLFilter, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(nsrlFilterMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, errors.Wrap(err, "cannot read filter header")
+	}
+	if string(magic) != nsrlFilterMagic {
+		return nil, errors.Errorf("invalid filter header '%s'", string(magic))
+	}
+	f := &NSRLFilter{}
+	for _, v := range []any{&f.m, &f.k, &f.count} {
+		if err := binary.Read(br, binary.LittleEndian, v); err != nil {
+			return nil, errors.Wrap(err, "cannot read filter header")
+		}
+	}
+	if f.m == 0 || f.m%64 != 0 || f.k == 0 {
+		return nil, errors.Errorf("invalid filter size m=%d, k=%d", f.m, f.k)
+	}
+	f.bits = make([]uint64, f.m/64)
+	if err := binary.Read(br, binary.LittleEndian, f.bits); err != nil {
+		return nil, errors.Wrap(err, "cannot read filter data")
+	}
+	return f, nil
+}
+
+func LoadNSRLFilter(filename string) (*NSRLFilter, error) {
+	fp, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open filter %s", filename)
+	}
+	defer fp.Close()
+	f, err := ReadNSRLFilter(fp)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read filter %s", filename)
+	}
+	return f, nil
+}
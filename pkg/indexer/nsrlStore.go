@@ -0,0 +1,128 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package indexer
+
+import (
+	"encoding/json"
+	"strings"
+
+	"emperror.dev/errors"
+	badger "github.com/dgraph-io/badger/v4"
+	"github.com/golang/snappy"
+)
+
+// NSRLStore gives access to the NSRL entries in the format of nsrl2badger
+type NSRLStore interface {
+	// Get returns the entries of key or nil, if key does not exist
+	Get(key string) ([]map[string]string, error)
+}
+
+func NewNSRLBadgerStore(db *badger.DB) *NSRLBadgerStore {
+	return &NSRLBadgerStore{db: db}
+}
+
+// NSRLBadgerStore reads the snappy compressed json entries of a badger database created by nsrl2badger
+type NSRLBadgerStore struct {
+	db *badger.DB
+}
+
+// getStringMap reads and decodes the value of key, nil if not found
+func getStringMap(txn *badger.Txn, key string) ([]map[string]string, error) {
+	var result []map[string]string
+	item, err := txn.Get([]byte(key))
+	if err != nil {
+		if err == badger.ErrKeyNotFound {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "cannot get %s", key)
+	}
+	if err := item.Value(func(val []byte) error {
+		jsonStr, err := snappy.Decode(nil, val)
+		if err != nil {
+			return errors.Wrapf(err, "cannot decompress snappy of %s", key)
+		}
+		if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
+			return errors.Wrapf(err, "cannot unmarshal %s for %s", jsonStr, key)
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.Wrapf(err, "cannot get data of %s", key)
+	}
+	return result, nil
+}
+
+func (s *NSRLBadgerStore) Get(key string) ([]map[string]string, error) {
+	var result []map[string]string
+	if err := s.db.View(func(txn *badger.Txn) error {
+		var err error
+		result, err = getStringMap(txn, key)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// NSRLMemoryStore keeps all entries in a map
+type NSRLMemoryStore map[string][]map[string]string
+
+func (s NSRLMemoryStore) Get(key string) ([]map[string]string, error) {
+	return s[key], nil
+}
+
+func NewNSRLFilterStore(filter *NSRLFilter, next NSRLStore) *NSRLFilterStore {
+	return &NSRLFilterStore{filter: filter, next: next}
+}
+
+// NSRLProbableStore is implemented by stores, which can tell that a key is probably known,
+// without being able to confirm it
+type NSRLProbableStore interface {
+	Probable(key string) bool
+}
+
+// NSRLFilterStore answers file lookups with a bloom filter and asks the next store only on a filter hit.
+// Without a next store, Get returns nil and a filter hit is reported by Probable only,
+// because the filter has false positives.
+type NSRLFilterStore struct {
+	filter *NSRLFilter
+	next   NSRLStore
+}
+
+func (s *NSRLFilterStore) Get(key string) ([]map[string]string, error) {
+	if strings.HasPrefix(key, NSRL_File) {
+		if !s.filter.Contains(key) {
+			return nil, nil
+		}
+	}
+	if s.next == nil {
+		return nil, nil
+	}
+	result, err := s.next.Get(key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get %s", key)
+	}
+	return result, nil
+}
+
+// Probable returns true, if a file key is in the filter and there is no store to confirm it
+func (s *NSRLFilterStore) Probable(key string) bool {
+	return s.next == nil && strings.HasPrefix(key, NSRL_File) && s.filter.Contains(key)
+}
+
+var (
+	_ NSRLStore         = &NSRLBadgerStore{}
+	_ NSRLStore         = NSRLMemoryStore{}
+	_ NSRLStore         = &NSRLFilterStore{}
+	_ NSRLProbableStore = &NSRLFilterStore{}
+)
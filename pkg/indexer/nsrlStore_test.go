@@ -0,0 +1,126 @@
+package indexer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/je4/utils/v2/pkg/checksum"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNSRLFilter(t *testing.T) {
+	const n = 10000
+	filter := NewNSRLFilter(n, 0.01)
+	for i := 0; i < n; i++ {
+		filter.Add(fmt.Sprintf("%s%040X", NSRL_File, i))
+	}
+
+	var buf bytes.Buffer
+	_, err := filter.WriteTo(&buf)
+	assert.NoError(t, err)
+	loaded, err := ReadNSRLFilter(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(n), loaded.Count())
+
+	for i := 0; i < n; i++ {
+		if !loaded.Contains(fmt.Sprintf("%s%040X", NSRL_File, i)) {
+			t.Fatalf("false negative for key %d", i)
+		}
+	}
+	var falsePositives int
+	for i := n; i < 2*n; i++ {
+		if loaded.Contains(fmt.Sprintf("%s%040X", NSRL_File, i)) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, n/20)
+}
+
+func TestNSRLFilterStore(t *testing.T) {
+	known := NSRL_File + "AAAA"
+	mem := NSRLMemoryStore{
+		known:            {{"FileName": "a.exe", "ProductCode": "1"}},
+		NSRL_PROD + "1":  {{"ProductName": "Tool"}},
+		NSRL_File + "BB": {{"FileName": "not in filter"}},
+	}
+	filter := NewNSRLFilter(10, 0.001)
+	filter.Add(known)
+
+	tests := []struct {
+		name string
+		next NSRLStore
+		key  string
+		want []map[string]string
+	}{
+		{name: "hit", next: mem, key: known, want: mem[known]},
+		{name: "filtered", next: mem, key: NSRL_File + "BB", want: nil},
+		{name: "product", next: mem, key: NSRL_PROD + "1", want: mem[NSRL_PROD+"1"]},
+		{name: "hit without store", next: nil, key: known, want: nil},
+		{name: "product without store", next: nil, key: NSRL_PROD + "1", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewNSRLFilterStore(filter, tt.next).Get(tt.key)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, result)
+		})
+	}
+}
+
+func TestActionNSRL_Probable(t *testing.T) {
+	known, err := checksum.Checksum(strings.NewReader("known"), checksum.DigestSHA1)
+	assert.NoError(t, err)
+	// an overfilled filter to find a checksum, which is a false positive
+	filter := NewNSRLFilter(10, 0.5)
+	filter.Add(NSRL_File + strings.ToUpper(known))
+	for i := 0; i < 30; i++ {
+		filter.Add(fmt.Sprintf("%s%040X", NSRL_File, i))
+	}
+	var falsePositive string
+	for i := 0; i < 1000 && falsePositive == ""; i++ {
+		content := fmt.Sprintf("unknown %d", i)
+		sha1sum, err := checksum.Checksum(strings.NewReader(content), checksum.DigestSHA1)
+		assert.NoError(t, err)
+		if filter.Contains(NSRL_File + strings.ToUpper(sha1sum)) {
+			falsePositive = content
+		}
+	}
+	if falsePositive == "" {
+		t.Fatal("no false positive found")
+	}
+	mem := NSRLMemoryStore{NSRL_File + strings.ToUpper(known): {{"FileName": "known.txt"}}}
+	logger := zerolog.Nop()
+
+	tests := []struct {
+		name         string
+		next         NSRLStore
+		content      string
+		wantKnown    bool
+		wantProbable bool
+	}{
+		{name: "filter only", content: "known", wantProbable: true},
+		{name: "false positive filter only", content: falsePositive, wantProbable: true},
+		{name: "confirmed", next: mem, content: "known", wantKnown: true},
+		{name: "false positive confirmed", next: mem, content: falsePositive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ad := NewActionDispatcher(nil)
+			NewActionNSRL(NameNSRL, NewNSRLFilterStore(filter, tt.next), ad, &logger)
+			result, err := ad.Stream(strings.NewReader(tt.content), []string{"test.txt"}, []string{NameNSRL})
+			if !assert.NoError(t, err) {
+				return
+			}
+			nsrlResult, ok := result.Metadata[NameNSRL].(*ActionNSRLResult)
+			if !assert.True(t, ok) {
+				return
+			}
+			assert.Equal(t, tt.wantKnown, nsrlResult.Known)
+			assert.Equal(t, tt.wantProbable, nsrlResult.Probable)
+			assert.Equal(t, tt.wantKnown, len(nsrlResult.Entries) > 0)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package indexer
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/je4/utils/v2/pkg/checksum"
+)
+
+const (
+	OCFLInventoryFile = "inventory.json"
+	OCFLExtensionDir  = "extensions"
+	OCFLExtensionName = "indexer"
+	OCFLSidecar       = "index.json"
+)
+
+// OCFLInventoryData contains the parts of an OCFL inventory needed for indexing
+type OCFLInventoryData struct {
+	ID              string              `json:"id"`
+	Type            string              `json:"type"`
+	DigestAlgorithm string              `json:"digestAlgorithm"`
+	Head            string              `json:"head"`
+	Manifest        map[string][]string `json:"manifest"`
+}
+
+// OCFLFileIndex is the result of one content file of an OCFL object
+type OCFLFileIndex struct {
+	Paths  []string  `json:"paths"`
+	Error  string    `json:"error,omitempty"`
+	Result *ResultV2 `json:"result,omitempty"`
+}
+
+// OCFLObjectIndex contains the results of all content files of an OCFL object keyed by digest
+type OCFLObjectIndex struct {
+	ID              string                    `json:"id"`
+	Head            string                    `json:"head"`
+	DigestAlgorithm string                    `json:"digestAlgorithm"`
+	Files           map[string]*OCFLFileIndex `json:"files"`
+}
+
+// ReadOCFLInventory reads the inventory of the object root
+func ReadOCFLInventory(fsys fs.FS) (*OCFLInventoryData, error) {
+	data, err := fs.ReadFile(fsys, OCFLInventoryFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read %s", OCFLInventoryFile)
+	}
+	var inventory = &OCFLInventoryData{}
+	if err := json.Unmarshal(data, inventory); err != nil {
+		return nil, errors.Wrapf(err, "cannot unmarshal %s", OCFLInventoryFile)
+	}
+	if !checksum.HashExists(checksum.DigestAlgorithm(inventory.DigestAlgorithm)) {
+		return nil, errors.Errorf("digest algorithm '%s' of object %s not supported", inventory.DigestAlgorithm, inventory.ID)
+	}
+	return inventory, nil
+}
+
+// IndexOCFLObject indexes every content file of the OCFL object in fsys once per digest
+// and checks the digests of the inventory. If ctx is done, the indexing is aborted and the error of ctx is returned.
+func IndexOCFLObject(ctx context.Context, ad *ActionDispatcher, fsys fs.FS, actions []string) (*OCFLObjectIndex, error) {
+	inventory, err := ReadOCFLInventory(fsys)
+	if err != nil {
+		return nil, err
+	}
+	index := &OCFLObjectIndex{
+		ID:              inventory.ID,
+		Head:            inventory.Head,
+		DigestAlgorithm: inventory.DigestAlgorithm,
+		Files:           map[string]*OCFLFileIndex{},
+	}
+	alg := checksum.DigestAlgorithm(inventory.DigestAlgorithm)
+	digests := make([]string, 0, len(inventory.Manifest))
+	for digest := range inventory.Manifest {
+		digests = append(digests, digest)
+	}
+	slices.Sort(digests)
+	for _, digest := range digests {
+		paths := inventory.Manifest[digest]
+		fi := &OCFLFileIndex{Paths: paths}
+		index.Files[strings.ToLower(digest)] = fi
+		if len(paths) == 0 {
+			fi.Error = "no content path"
+			continue
+		}
+		result, sum, err := indexOCFLFile(ctx, ad, fsys, paths[0], alg, actions)
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrapf(err, "indexing of %s aborted", inventory.ID)
+		}
+		if err != nil {
+			fi.Error = err.Error()
+			continue
+		}
+		fi.Result = result
+		if !strings.EqualFold(sum, digest) {
+			fi.Error = fmt.Sprintf("%s digest mismatch: inventory %s, content %s", alg, digest, sum)
+		} else if cs, ok := result.Checksum[string(alg)]; ok && !strings.EqualFold(cs, digest) {
+			fi.Error = fmt.Sprintf("%s digest mismatch: inventory %s, indexer %s", alg, digest, cs)
+		}
+	}
+	return index, nil
+}
+
+func indexOCFLFile(ctx context.Context, ad *ActionDispatcher, fsys fs.FS, name string, alg checksum.DigestAlgorithm, actions []string) (*ResultV2, string, error) {
+	fp, err := fsys.Open(name)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "cannot open %s", name)
+	}
+	defer fp.Close()
+	h, err := checksum.GetHash(alg)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "cannot create %s hash", alg)
+	}
+	result, err := ad.StreamContext(ctx, io.TeeReader(fp, h), []string{path.Base(name)}, actions)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "cannot index %s", name)
+	}
+	return result, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// WriteOCFLSidecar writes the index to extensions/<extension>/index.json of the object root
+func WriteOCFLSidecar(objectRoot string, extension string, index *OCFLObjectIndex) (string, error) {
+	if extension == "" {
+		extension = OCFLExtensionName
+	}
+	dir := filepath.Join(objectRoot, OCFLExtensionDir, extension)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.Wrapf(err, "cannot create %s", dir)
+	}
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot marshal index of %s", index.ID)
+	}
+	filename := filepath.Join(dir, OCFLSidecar)
+	tmpName := filename + ".tmp"
+	if err := os.WriteFile(tmpName, data, 0644); err != nil {
+		return "", errors.Wrapf(err, "cannot write %s", tmpName)
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		return "", errors.Wrapf(err, "cannot rename %s", tmpName)
+	}
+	return filename, nil
+}
@@ -0,0 +1,75 @@
+package indexer
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/je4/utils/v2/pkg/checksum"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexOCFLObject(t *testing.T) {
+	sum := func(data string) string {
+		s := sha512.Sum512([]byte(data))
+		return hex.EncodeToString(s[:])
+	}
+	wrong := sum("something else")
+	inventory, err := json.Marshal(map[string]any{
+		"id":              "info:test/object",
+		"type":            "https://ocfl.io/1.1/spec/#inventory",
+		"digestAlgorithm": "sha512",
+		"head":            "v2",
+		"manifest": map[string][]string{
+			sum("hello world"): {"v1/content/a.txt", "v2/content/copy.txt"},
+			wrong:              {"v1/content/b.txt"},
+		},
+	})
+	assert.NoError(t, err)
+	fsys := fstest.MapFS{
+		"inventory.json":      {Data: inventory},
+		"v1/content/a.txt":    {Data: []byte("hello world")},
+		"v2/content/copy.txt": {Data: []byte("hello world")},
+		"v1/content/b.txt":    {Data: []byte("changed")},
+	}
+
+	ad := NewActionDispatcher(nil)
+	NewActionChecksum("checksum", []checksum.DigestAlgorithm{checksum.DigestSHA512}, ad)
+	index, err := IndexOCFLObject(context.Background(), ad, fsys, []string{"checksum"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "info:test/object", index.ID)
+	assert.Len(t, index.Files, 2)
+	if fi := index.Files[sum("hello world")]; assert.NotNil(t, fi) {
+		assert.Empty(t, fi.Error)
+		assert.Len(t, fi.Paths, 2)
+		assert.Equal(t, uint64(11), fi.Result.Size)
+	}
+	if fi := index.Files[wrong]; assert.NotNil(t, fi) {
+		assert.Contains(t, fi.Error, "digest mismatch")
+	}
+
+	root := t.TempDir()
+	filename, err := WriteOCFLSidecar(root, "", index)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "extensions", "indexer", "index.json"), filename)
+	data, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	var read OCFLObjectIndex
+	assert.NoError(t, json.Unmarshal(data, &read))
+	assert.Len(t, read.Files, 2)
+
+	_, err = IndexOCFLObject(context.Background(), ad, fstest.MapFS{}, nil)
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = IndexOCFLObject(ctx, ad, fsys, []string{"checksum"})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,227 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package indexer
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"emperror.dev/errors"
+)
+
+const (
+	PREMISNamespace      = "http://www.loc.gov/premis/v3"
+	PREMISSchemaLocation = "http://www.loc.gov/premis/v3 https://www.loc.gov/standards/premis/premis.xsd"
+	// IndexerNamespace is the namespace of the action metadata in the PREMIS extensions
+	IndexerNamespace = "https://github.com/ocfl-archive/indexer"
+)
+
+// premisExtensionActions are the actions, whose metadata is added as objectCharacteristicsExtension
+var premisExtensionActions = []string{NameFFProbe, NameIdentify, NameTika}
+
+// premisDigestAlgorithms maps the checksum names to the PREMIS vocabulary
+var premisDigestAlgorithms = map[string]string{
+	"md5":    "MD5",
+	"sha1":   "SHA-1",
+	"sha256": "SHA-256",
+	"sha384": "SHA-384",
+	"sha512": "SHA-512",
+}
+
+// PREMIS is a PREMIS 3 container with one file object and the agents of the actions
+type PREMIS struct {
+	XMLName        xml.Name        `xml:"premis:premis"`
+	XMLNSPremis    string          `xml:"xmlns:premis,attr"`
+	XMLNSXSI       string          `xml:"xmlns:xsi,attr"`
+	SchemaLocation string          `xml:"xsi:schemaLocation,attr,omitempty"`
+	Version        string          `xml:"version,attr"`
+	Objects        []*PREMISObject `xml:"premis:object"`
+	Agents         []*PREMISAgent  `xml:"premis:agent"`
+}
+
+type PREMISObject struct {
+	XSIType         string                      `xml:"xsi:type,attr"`
+	Identifiers     []PREMISObjectIdentifier    `xml:"premis:objectIdentifier"`
+	Characteristics PREMISObjectCharacteristics `xml:"premis:objectCharacteristics"`
+	OriginalName    string                      `xml:"premis:originalName,omitempty"`
+}
+
+type PREMISObjectIdentifier struct {
+	Type  string `xml:"premis:objectIdentifierType"`
+	Value string `xml:"premis:objectIdentifierValue"`
+}
+
+type PREMISObjectCharacteristics struct {
+	CompositionLevel int               `xml:"premis:compositionLevel"`
+	Fixity           []PREMISFixity    `xml:"premis:fixity"`
+	Size             uint64            `xml:"premis:size"`
+	Formats          []PREMISFormat    `xml:"premis:format"`
+	Extensions       []PREMISExtension `xml:"premis:objectCharacteristicsExtension"`
+}
+
+type PREMISFixity struct {
+	Algorithm string `xml:"premis:messageDigestAlgorithm"`
+	Digest    string `xml:"premis:messageDigest"`
+}
+
+type PREMISFormat struct {
+	Designation *PREMISFormatDesignation `xml:"premis:formatDesignation,omitempty"`
+	Registry    *PREMISFormatRegistry    `xml:"premis:formatRegistry,omitempty"`
+}
+
+type PREMISFormatDesignation struct {
+	Name string `xml:"premis:formatName"`
+}
+
+type PREMISFormatRegistry struct {
+	Name string `xml:"premis:formatRegistryName"`
+	Key  string `xml:"premis:formatRegistryKey"`
+	Role string `xml:"premis:formatRegistryRole,omitempty"`
+}
+
+// PREMISExtension contains the metadata of one action as json
+type PREMISExtension struct {
+	Metadata PREMISActionMetadata `xml:"indexer:metadata"`
+}
+
+type PREMISActionMetadata struct {
+	XMLNS  string `xml:"xmlns:indexer,attr"`
+	Action string `xml:"action,attr"`
+	Data   string `xml:",chardata"`
+}
+
+type PREMISAgent struct {
+	Identifier PREMISAgentIdentifier `xml:"premis:agentIdentifier"`
+	Name       string                `xml:"premis:agentName"`
+	Type       string                `xml:"premis:agentType"`
+	Version    string                `xml:"premis:agentVersion,omitempty"`
+}
+
+type PREMISAgentIdentifier struct {
+	Type  string `xml:"premis:agentIdentifierType"`
+	Value string `xml:"premis:agentIdentifierValue"`
+}
+
+// NewPREMIS maps the result to a PREMIS file object with a local identifier. Every action, which contributed
+// to the result, is added as software agent with its version from versions.
+func NewPREMIS(result *ResultV2, identifier string, originalName string, versions map[string]string) (*PREMIS, error) {
+	object, err := NewPREMISObject(result, identifier, originalName)
+	if err != nil {
+		return nil, err
+	}
+	premis := &PREMIS{
+		XMLNSPremis:    PREMISNamespace,
+		XMLNSXSI:       "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: PREMISSchemaLocation,
+		Version:        "3.0",
+		Objects:        []*PREMISObject{object},
+	}
+	for _, action := range resultActions(result) {
+		premis.Agents = append(premis.Agents, &PREMISAgent{
+			Identifier: PREMISAgentIdentifier{Type: "local", Value: action},
+			Name:       action,
+			Type:       "software",
+			Version:    versions[action],
+		})
+	}
+	return premis, nil
+}
+
+// NewPREMISObject maps the result to a PREMIS file object with a local identifier
+func NewPREMISObject(result *ResultV2, identifier string, originalName string) (*PREMISObject, error) {
+	object := &PREMISObject{
+		XSIType:      "premis:file",
+		Identifiers:  []PREMISObjectIdentifier{{Type: "local", Value: identifier}},
+		OriginalName: originalName,
+		Characteristics: PREMISObjectCharacteristics{
+			Size: result.Size,
+		},
+	}
+	for _, alg := range slices.Sorted(maps.Keys(result.Checksum)) {
+		object.Characteristics.Fixity = append(object.Characteristics.Fixity, PREMISFixity{Algorithm: digestAlgorithm(alg), Digest: result.Checksum[alg]})
+	}
+
+	// the first format contains the mimetype and the main pronom, additional pronoms get their own format
+	format := PREMISFormat{}
+	if result.Mimetype != "" {
+		format.Designation = &PREMISFormatDesignation{Name: result.Mimetype}
+	}
+	pronoms := slices.Clone(result.Pronoms)
+	if result.Pronom != "" {
+		pronoms = append([]string{result.Pronom}, slices.DeleteFunc(pronoms, func(p string) bool { return p == result.Pronom })...)
+	}
+	if len(pronoms) > 0 {
+		format.Registry = &PREMISFormatRegistry{Name: "PRONOM", Key: pronoms[0], Role: "specification"}
+		pronoms = pronoms[1:]
+	}
+	if format.Designation == nil && format.Registry == nil {
+		format.Designation = &PREMISFormatDesignation{Name: "application/octet-stream"}
+	}
+	object.Characteristics.Formats = append(object.Characteristics.Formats, format)
+	for _, pronom := range pronoms {
+		object.Characteristics.Formats = append(object.Characteristics.Formats, PREMISFormat{
+			Registry: &PREMISFormatRegistry{Name: "PRONOM", Key: pronom, Role: "specification"},
+		})
+	}
+
+	for _, action := range premisExtensionActions {
+		metadata, ok := result.Metadata[action]
+		if !ok {
+			continue
+		}
+		data, err := json.Marshal(metadata)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot marshal metadata of action %s", action)
+		}
+		object.Characteristics.Extensions = append(object.Characteristics.Extensions, PREMISExtension{
+			Metadata: PREMISActionMetadata{XMLNS: IndexerNamespace, Action: action, Data: string(data)},
+		})
+	}
+	return object, nil
+}
+
+// digestAlgorithm returns the PREMIS name of a checksum algorithm
+func digestAlgorithm(alg string) string {
+	if name, ok := premisDigestAlgorithms[strings.ToLower(alg)]; ok {
+		return name
+	}
+	return strings.ToUpper(alg)
+}
+
+// resultActions returns the sorted names of the actions, which contributed to the result
+func resultActions(result *ResultV2) []string {
+	actions := slices.Collect(maps.Keys(result.Metadata))
+	if len(result.Checksum) > 0 && !slices.Contains(actions, NameChecksum) {
+		actions = append(actions, NameChecksum)
+	}
+	slices.Sort(actions)
+	return actions
+}
+
+// Write writes the PREMIS xml document to w
+func (p *PREMIS) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return errors.Wrap(err, "cannot write xml header")
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(p); err != nil {
+		return errors.Wrap(err, "cannot encode premis")
+	}
+	return errors.WithStack(enc.Close())
+}
@@ -0,0 +1,85 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// premisElement is a namespace aware view of the written document
+type premisElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr      `xml:",any,attr"`
+	Children []premisElement `xml:",any"`
+	Text     string          `xml:",chardata"`
+}
+
+func (e premisElement) child(local string) *premisElement {
+	for i := range e.Children {
+		if e.Children[i].XMLName.Local == local {
+			return &e.Children[i]
+		}
+	}
+	return nil
+}
+
+func (e premisElement) childNames() []string {
+	var names = []string{}
+	for _, c := range e.Children {
+		names = append(names, c.XMLName.Local)
+	}
+	return names
+}
+
+// testPREMIS writes the PREMIS document of a characterised video
+func testPREMIS(t *testing.T) []byte {
+	t.Helper()
+	result := NewResultV2()
+	result.Mimetype = "video/mp4"
+	result.Pronom = "fmt/199"
+	result.Pronoms = []string{"fmt/199", "x-fmt/384"}
+	result.Size = 1234
+	result.Checksum = map[string]string{"sha512": "abc", "md5": "def"}
+	result.Metadata = map[string]any{
+		NameFFProbe:   map[string]any{"format": map[string]any{"duration": "1.5"}},
+		NameSiegfried: []string{"fmt/199"},
+		NameChecksum:  map[string]string{"sha512": "abc", "md5": "def"},
+	}
+	premis, err := NewPREMIS(result, "obj-1", "test.mp4", map[string]string{NameSiegfried: "1.11.4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := premis.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestPREMIS(t *testing.T) {
+	var doc premisElement
+	if !assert.NoError(t, xml.Unmarshal(testPREMIS(t), &doc)) {
+		return
+	}
+	assert.Equal(t, xml.Name{Space: PREMISNamespace, Local: "premis"}, doc.XMLName)
+	// the order of the elements is given by the PREMIS schema
+	assert.Equal(t, []string{"object", "agent", "agent", "agent"}, doc.childNames())
+	object := doc.child("object")
+	assert.Equal(t, []string{"objectIdentifier", "objectCharacteristics", "originalName"}, object.childNames())
+	characteristics := object.child("objectCharacteristics")
+	assert.Equal(t, []string{"compositionLevel", "fixity", "fixity", "size", "format", "format", "objectCharacteristicsExtension"}, characteristics.childNames())
+	assert.Equal(t, "MD5", characteristics.Children[1].child("messageDigestAlgorithm").Text)
+	assert.Equal(t, "1234", characteristics.child("size").Text)
+	registry := characteristics.child("format").child("formatRegistry")
+	assert.Equal(t, "PRONOM", registry.child("formatRegistryName").Text)
+	assert.Equal(t, "fmt/199", registry.child("formatRegistryKey").Text)
+	extension := characteristics.child("objectCharacteristicsExtension").child("metadata")
+	assert.Equal(t, IndexerNamespace, extension.XMLName.Space)
+	assert.JSONEq(t, `{"format": {"duration": "1.5"}}`, extension.Text)
+	// the agents are sorted by name: checksum, ffprobe, siegfried
+	agent := doc.Children[3]
+	assert.Equal(t, []string{"agentIdentifier", "agentName", "agentType", "agentVersion"}, agent.childNames())
+	assert.Equal(t, "1.11.4", agent.child("agentVersion").Text)
+}
@@ -0,0 +1,205 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package indexer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	badger "github.com/dgraph-io/badger/v4"
+	"github.com/golang/snappy"
+)
+
+// ResultCache stores results by content digest and configuration fingerprint
+type ResultCache interface {
+	// Get returns the result of key or nil, if key does not exist
+	Get(key string) (*ResultV2, error)
+	Set(key string, result *ResultV2) error
+}
+
+// resultCacheKey combines the sha256 content digest with the fingerprint of the configuration,
+// the requested actions and the file extension, which is used by some actions
+func resultCacheKey(digest string, fingerprint string, filename string, actions []string) string {
+	actions = slices.Clone(actions)
+	slices.Sort(actions)
+	h := sha256.New()
+	h.Write([]byte(fingerprint))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.Join(actions, ",")))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.ToLower(filepath.Ext(filename))))
+	return digest + "-" + hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// spool writes reader to a temporary file with the extension of filename and returns the file and the sha256 digest of the content
+func spool(reader io.Reader, tempDir string, filename string) (*os.File, string, error) {
+	tmpFile, err := os.CreateTemp(tempDir, "cache-*"+filepath.Ext(filename))
+	if err != nil {
+		return nil, "", errors.Wrap(err, "cannot create temporary file")
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmpFile, h), reader); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		return nil, "", errors.Wrapf(err, "cannot write to '%s'", tmpFile.Name())
+	}
+	if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		return nil, "", errors.Wrapf(err, "cannot seek '%s'", tmpFile.Name())
+	}
+	return tmpFile, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func fileDigest(filename string) (string, error) {
+	fp, err := os.Open(filename)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot open '%s'", filename)
+	}
+	defer fp.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, fp); err != nil {
+		return "", errors.Wrapf(err, "cannot read '%s'", filename)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func NewResultCacheBadger(db *badger.DB, ttl time.Duration) *ResultCacheBadger {
+	return &ResultCacheBadger{db: db, ttl: ttl}
+}
+
+// ResultCacheBadger stores snappy compressed json results in a badger database.
+// With a ttl > 0 the entries expire.
+type ResultCacheBadger struct {
+	db  *badger.DB
+	ttl time.Duration
+}
+
+func (rc *ResultCacheBadger) Get(key string) (*ResultV2, error) {
+	var result *ResultV2
+	if err := rc.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				return nil
+			}
+			return errors.Wrapf(err, "cannot get %s", key)
+		}
+		return item.Value(func(val []byte) error {
+			data, err := snappy.Decode(nil, val)
+			if err != nil {
+				return errors.Wrapf(err, "cannot decompress snappy of %s", key)
+			}
+			result = &ResultV2{}
+			if err := json.Unmarshal(data, result); err != nil {
+				return errors.Wrapf(err, "cannot unmarshal result of %s", key)
+			}
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (rc *ResultCacheBadger) Set(key string, result *ResultV2) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return errors.Wrapf(err, "cannot marshal result of %s", key)
+	}
+	entry := badger.NewEntry([]byte(key), snappy.Encode(nil, data))
+	if rc.ttl > 0 {
+		entry = entry.WithTTL(rc.ttl)
+	}
+	if err := rc.db.Update(func(txn *badger.Txn) error {
+		return txn.SetEntry(entry)
+	}); err != nil {
+		return errors.Wrapf(err, "cannot store result of %s", key)
+	}
+	return nil
+}
+
+func NewResultCacheDir(dir string) (*ResultCacheDir, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrapf(err, "cannot create cache folder %s", dir)
+	}
+	return &ResultCacheDir{dir: dir}, nil
+}
+
+// ResultCacheDir stores json results as files in a folder
+type ResultCacheDir struct {
+	dir string
+}
+
+func (rc *ResultCacheDir) filename(key string) string {
+	return filepath.Join(rc.dir, key[:2], key+".json")
+}
+
+func (rc *ResultCacheDir) Get(key string) (*ResultV2, error) {
+	data, err := os.ReadFile(rc.filename(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "cannot read %s", rc.filename(key))
+	}
+	var result = &ResultV2{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, errors.Wrapf(err, "cannot unmarshal %s", rc.filename(key))
+	}
+	return result, nil
+}
+
+func (rc *ResultCacheDir) Set(key string, result *ResultV2) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return errors.Wrapf(err, "cannot marshal result of %s", key)
+	}
+	filename := rc.filename(key)
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return errors.Wrapf(err, "cannot create %s", filepath.Dir(filename))
+	}
+	// parallel workers may store the same key
+	tmpFile, err := os.CreateTemp(filepath.Dir(filename), key+"-*.tmp")
+	if err != nil {
+		return errors.Wrapf(err, "cannot create temporary file for %s", filename)
+	}
+	tmpName := tmpFile.Name()
+	_, err = tmpFile.Write(data)
+	if err2 := tmpFile.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		os.Remove(tmpName)
+		return errors.Wrapf(err, "cannot write %s", tmpName)
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		os.Remove(tmpName)
+		return errors.Wrapf(err, "cannot rename %s", tmpName)
+	}
+	return nil
+}
+
+var (
+	_ ResultCache = &ResultCacheBadger{}
+	_ ResultCache = &ResultCacheDir{}
+)
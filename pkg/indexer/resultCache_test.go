@@ -0,0 +1,107 @@
+package indexer
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	badger "github.com/dgraph-io/badger/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// countAction counts the calls of Stream
+type countAction struct {
+	calls int
+}
+
+func (ca *countAction) Stream(contentType string, reader io.Reader, filename string) (*ResultV2, error) {
+	ca.calls++
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	result := NewResultV2()
+	result.Metadata["count"] = map[string]any{"length": len(data)}
+	return result, nil
+}
+func (ca *countAction) DoV2(filename string) (*ResultV2, error) {
+	fp, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	return ca.Stream("", fp, filename)
+}
+func (ca *countAction) CanHandle(contentType string, filename string) bool { return true }
+func (ca *countAction) GetName() string                                    { return "count" }
+func (ca *countAction) GetCaps() ActionCapability                          { return ACTFILEFULL | ACTSTREAM }
+func (ca *countAction) GetWeight() uint                                    { return 100 }
+
+func TestResultCache(t *testing.T) {
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer db.Close()
+	dirCache, err := NewResultCacheDir(t.TempDir())
+	assert.NoError(t, err)
+
+	filename := filepath.Join(t.TempDir(), "test.txt")
+	assert.NoError(t, os.WriteFile(filename, []byte("hello world"), 0644))
+
+	for name, cache := range map[string]ResultCache{"badger": NewResultCacheBadger(db, 0), "dir": dirCache} {
+		t.Run(name, func(t *testing.T) {
+			ca := &countAction{}
+			ad := NewActionDispatcher(nil)
+			ad.RegisterAction(ca)
+			ad.SetCache(cache, "config1", t.TempDir())
+
+			for i := 0; i < 2; i++ {
+				result, err := ad.Stream(strings.NewReader("hello world"), []string{"test.txt"}, []string{"count"})
+				if assert.NoError(t, err) {
+					assert.Equal(t, uint64(11), result.Size)
+					assert.NotNil(t, result.Errors)
+				}
+			}
+			assert.Equal(t, 1, ca.calls)
+
+			// other extension and other content are new entries
+			_, err := ad.Stream(strings.NewReader("hello world"), []string{"test.bin"}, []string{"count"})
+			assert.NoError(t, err)
+			_, err = ad.Stream(strings.NewReader("hello"), []string{"test.txt"}, []string{"count"})
+			assert.NoError(t, err)
+			assert.Equal(t, 3, ca.calls)
+			// same content and extension as file
+			_, err = ad.DoV2(filename, nil, []string{"count"})
+			assert.NoError(t, err)
+			assert.Equal(t, 3, ca.calls)
+
+			// changed fingerprint invalidates the entries
+			ad.SetCache(cache, "config2", t.TempDir())
+			_, err = ad.Stream(strings.NewReader("hello world"), []string{"test.txt"}, []string{"count"})
+			assert.NoError(t, err)
+			assert.Equal(t, 4, ca.calls)
+
+			// the volatile part is read for every lookup, without value the result is not cached
+			volatile, ok := "sig1", true
+			ad.SetCacheVolatile(func(actions []string) (string, bool) { return volatile, ok })
+			for i := 0; i < 2; i++ {
+				_, err = ad.Stream(strings.NewReader("hello world"), []string{"test.txt"}, []string{"count"})
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, 5, ca.calls)
+			volatile = "sig2"
+			_, err = ad.Stream(strings.NewReader("hello world"), []string{"test.txt"}, []string{"count"})
+			assert.NoError(t, err)
+			assert.Equal(t, 6, ca.calls)
+			ok = false
+			for i := 0; i < 2; i++ {
+				_, err = ad.Stream(strings.NewReader("hello world"), []string{"test.txt"}, []string{"count"})
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, 8, ca.calls)
+		})
+	}
+}
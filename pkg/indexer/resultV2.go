@@ -16,6 +16,10 @@ type ResultV2 struct {
 	Metadata  map[string]any    `json:"metadata"`
 	Type      string            `json:"type"`
 	Subtype   string            `json:"subtype"`
+	// Partial is set, if only the head of the data has been indexed
+	Partial bool `json:"partial,omitempty"`
+	// ActionErrors are the structured errors of the failed actions
+	ActionErrors []*ActionError `json:"actionerrors,omitempty"`
 }
 
 func NewResultV2() *ResultV2 {
@@ -28,6 +32,15 @@ func NewResultV2() *ResultV2 {
 	}
 }
 
+// AddError stores the error of an action as message and as structured error
+func (v *ResultV2) AddError(action string, err error) {
+	if v.Errors == nil {
+		v.Errors = map[string]string{}
+	}
+	v.Errors[action] = err.Error()
+	v.ActionErrors = append(v.ActionErrors, NewActionError(errorIDOf(err), action, err.Error()))
+}
+
 func (v *ResultV2) Merge(r *ResultV2) {
 	if r == nil {
 		return
@@ -60,6 +73,9 @@ func (v *ResultV2) Merge(r *ResultV2) {
 	if r.Duration > v.Duration {
 		v.Duration = r.Duration
 	}
+	if r.Partial {
+		v.Partial = true
+	}
 	if r.Size > v.Size {
 		v.Size = r.Size
 	}
@@ -74,6 +90,7 @@ func (v *ResultV2) Merge(r *ResultV2) {
 			v.Errors[k] = e
 		}
 	}
+	v.ActionErrors = append(v.ActionErrors, r.ActionErrors...)
 	if r.Type != "" {
 		v.Type = r.Type
 		v.Subtype = r.Subtype
@@ -0,0 +1,130 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package indexer
+
+import (
+	"context"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/ocfl-archive/indexer/v3/pkg/indexer"
+
+// tracePropagator propagates the trace context from and to the services, W3C trace context by default
+var tracePropagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+// SetTracePropagator replaces the W3C trace context propagator, e.g. with otel.GetTextMapPropagator()
+func SetTracePropagator(propagator propagation.TextMapPropagator) {
+	tracePropagator = propagator
+}
+
+// TracePropagator returns the propagator used for incoming and outgoing trace contexts
+func TracePropagator() propagation.TextMapPropagator {
+	return tracePropagator
+}
+
+// SetTracerProvider sets the provider of the spans. Without provider, the global provider of otel is used,
+// which does nothing, if no exporter is configured.
+func (ad *ActionDispatcher) SetTracerProvider(tp trace.TracerProvider) {
+	ad.tracerProvider = tp
+}
+
+// startSpan starts a span for an indexing call of the dispatcher
+func (ad *ActionDispatcher) startSpan(ctx context.Context, name string, stateFiles []string, actions []string) (context.Context, trace.Span) {
+	tp := ad.tracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	attrs := []attribute.KeyValue{attribute.StringSlice("indexer.actions", actions)}
+	if len(stateFiles) > 0 {
+		attrs = append(attrs, attribute.String("indexer.filename", stateFiles[0]))
+	}
+	return tp.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// startChildSpan starts a span with the tracer provider of the span in ctx
+func startChildSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return trace.SpanFromContext(ctx).TracerProvider().Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records the identification of result or err and ends the span
+func endSpan(span trace.Span, result *ResultV2, err error) {
+	defer span.End()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	if result == nil {
+		return
+	}
+	span.SetAttributes(
+		attribute.String("indexer.mimetype", result.Mimetype),
+		attribute.String("indexer.pronom", result.Pronom),
+		attribute.Int64("indexer.size", int64(result.Size)),
+	)
+	if result.Partial {
+		span.SetAttributes(attribute.Bool("indexer.partial", true))
+	}
+}
+
+// endActionSpan records the result of an action and ends the span
+func endActionSpan(span trace.Span, action string, result *ResultV2, err error) {
+	defer span.End()
+	span.SetAttributes(attribute.String("indexer.status", actionStatus(action, result, err)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	if result == nil {
+		return
+	}
+	if msg := result.Errors[action]; msg != "" {
+		span.SetStatus(codes.Error, msg)
+	}
+	if len(result.Mimetypes) > 0 {
+		span.SetAttributes(attribute.StringSlice("indexer.mimetypes", result.Mimetypes))
+	}
+	if len(result.Pronoms) > 0 {
+		span.SetAttributes(attribute.StringSlice("indexer.pronoms", result.Pronoms))
+	}
+}
+
+// runCommand runs cmd in a span, which records the exit code
+func runCommand(ctx context.Context, cmd *exec.Cmd) error {
+	_, span := startChildSpan(ctx, "exec "+filepath.Base(cmd.Path), attribute.String("process.executable.name", filepath.Base(cmd.Path)))
+	defer span.End()
+	err := cmd.Run()
+	if cmd.ProcessState != nil {
+		span.SetAttributes(attribute.Int("process.exit.code", cmd.ProcessState.ExitCode()))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// injectTrace propagates the trace context of ctx to an outgoing request
+func injectTrace(ctx context.Context, req *http.Request) {
+	tracePropagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+}
@@ -0,0 +1,57 @@
+package indexer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.Write([]byte(`{"mimetype": "text/x-test"}`))
+	}))
+	defer server.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ad := NewActionDispatcher(nil)
+	ad.SetTracerProvider(tp)
+	ad.RegisterAction(&stageAction{name: "ident", caps: ACTSTREAM | ACTIDENT, mimetype: "video/x-test"})
+	_, err := NewActionExternal("ext", server.URL, ACTSTREAM, EACTSTREAMPOST, "", map[string]string{"mimetype": "mimetype"}, 0, ad)
+	assert.NoError(t, err)
+
+	data := strings.Repeat("plain text ", 100)
+	_, err = ad.StreamContext(context.Background(), strings.NewReader(data), []string{"test.txt"}, []string{"ident", "ext"})
+	assert.NoError(t, err)
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	root, ok := spans["Stream"]
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Contains(t, root.Attributes, attribute.Int64("indexer.size", int64(len(data))))
+	assert.Contains(t, root.Attributes, attribute.String("indexer.filename", "test.txt"))
+	for _, name := range []string{"ident", "ext"} {
+		span, ok := spans[name]
+		if !assert.True(t, ok, name) {
+			continue
+		}
+		assert.Equal(t, root.SpanContext.SpanID(), span.Parent.SpanID())
+		assert.Contains(t, span.Attributes, attribute.String("indexer.status", MetricStatusSuccess))
+		assert.Contains(t, span.Attributes, attribute.Int64("indexer.bytes", int64(len(data))))
+	}
+	// the external action gets the trace context of its span
+	assert.Contains(t, traceparent, spans["ext"].SpanContext.SpanID().String())
+}
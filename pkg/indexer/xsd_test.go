@@ -0,0 +1,48 @@
+//go:build xsd
+
+package indexer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// validateXSD validates data with xmllint against a schema in testdata/xsd, which needs the official schemas.
+// The test fails, if xmllint or one of the official schemas is missing, see testdata/xsd/README.md.
+func validateXSD(t *testing.T, xsd string, official []string, data []byte) {
+	t.Helper()
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		t.Fatal("xmllint is needed for the schema tests")
+	}
+	dir, err := filepath.Abs(filepath.Join("testdata", "xsd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range official {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("official schema %s missing: %v", name, err)
+		}
+	}
+	schema := filepath.Join(dir, xsd)
+	filename := filepath.Join(t.TempDir(), "document.xml")
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// the catalog resolves the imports of the schemas to testdata/xsd
+	cmd := exec.Command(xmllint, "--noout", "--nonet", "--schema", schema, filename)
+	cmd.Env = append(os.Environ(), "XML_CATALOG_FILES="+filepath.Join(dir, "catalog.xml"))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%s: %v\n%s", xsd, err, out)
+	}
+}
+
+func TestPREMISSchema(t *testing.T) {
+	validateXSD(t, "premis.xsd", []string{"premis.xsd"}, testPREMIS(t))
+}
+
+func TestMETSSchema(t *testing.T) {
+	validateXSD(t, "mets-premis.xsd", []string{"mets.xsd", "xlink.xsd", "premis.xsd"}, testMETS(t))
+}
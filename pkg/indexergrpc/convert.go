@@ -0,0 +1,245 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package indexergrpc
+
+import (
+	"encoding/json"
+
+	"emperror.dev/errors"
+	ffmpeg_models "github.com/je4/goffmpeg/models"
+	"github.com/je4/utils/v2/pkg/checksum"
+	"github.com/ocfl-archive/indexer/v3/pkg/indexer"
+	pb "github.com/ocfl-archive/indexer/v3/pkg/indexerproto"
+	"github.com/richardlehane/siegfried/pkg/core"
+	"github.com/richardlehane/siegfried/pkg/pronom"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ResultToProto converts the indexer result to its grpc message
+func ResultToProto(result *indexer.ResultV2) (*pb.Result, error) {
+	r := &pb.Result{
+		Errors:    result.Errors,
+		Mimetype:  result.Mimetype,
+		Mimetypes: result.Mimetypes,
+		Pronom:    result.Pronom,
+		Pronoms:   result.Pronoms,
+		Checksum:  result.Checksum,
+		Width:     uint64(result.Width),
+		Height:    uint64(result.Height),
+		Duration:  uint64(result.Duration),
+		Size:      result.Size,
+		Partial:   result.Partial,
+		Metadata:  map[string]*pb.Metadata{},
+		Type:      result.Type,
+		Subtype:   result.Subtype,
+	}
+	for name, data := range result.Metadata {
+		m, err := metadataToProto(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot convert metadata of action %s", name)
+		}
+		r.Metadata[name] = m
+	}
+	for _, ae := range result.ActionErrors {
+		r.ActionErrors = append(r.ActionErrors, &pb.ActionError{
+			Id:        string(ae.ID),
+			Action:    ae.Action,
+			Message:   ae.Message,
+			Severity:  ae.Severity,
+			Retriable: ae.Retriable,
+		})
+	}
+	return r, nil
+}
+
+func metadataToProto(data any) (*pb.Metadata, error) {
+	switch m := data.(type) {
+	case []core.Identification:
+		return &pb.Metadata{Metadata: &pb.Metadata_Siegfried{Siegfried: siegfriedToProto(m)}}, nil
+	case ffmpeg_models.Metadata:
+		return &pb.Metadata{Metadata: &pb.Metadata_Ffprobe{Ffprobe: ffprobeToProto(&m)}}, nil
+	case indexer.FullMagickResult:
+		identify, err := identifyToProto(&m)
+		if err != nil {
+			return nil, err
+		}
+		return &pb.Metadata{Metadata: &pb.Metadata_Identify{Identify: identify}}, nil
+	case map[checksum.DigestAlgorithm]string:
+		checksums := map[string]string{}
+		for alg, val := range m {
+			checksums[string(alg)] = val
+		}
+		return &pb.Metadata{Metadata: &pb.Metadata_Checksum{Checksum: &pb.ChecksumMetadata{Checksums: checksums}}}, nil
+	case []map[string]any:
+		tika := &pb.TikaMetadata{}
+		for _, doc := range m {
+			s, err := toStruct(doc)
+			if err != nil {
+				return nil, err
+			}
+			tika.Documents = append(tika.Documents, s)
+		}
+		return &pb.Metadata{Metadata: &pb.Metadata_Tika{Tika: tika}}, nil
+	default:
+		v, err := toValue(m)
+		if err != nil {
+			return nil, err
+		}
+		return &pb.Metadata{Metadata: &pb.Metadata_Other{Other: v}}, nil
+	}
+}
+
+func siegfriedToProto(ident []core.Identification) *pb.SiegfriedMetadata {
+	sf := &pb.SiegfriedMetadata{}
+	for _, id := range ident {
+		pid, ok := id.(pronom.Identification)
+		if !ok {
+			continue
+		}
+		sf.Identifications = append(sf.Identifications, &pb.SiegfriedIdentification{
+			Namespace: pid.Namespace,
+			Id:        pid.ID,
+			Name:      pid.Name,
+			Version:   pid.Version,
+			Mime:      pid.MIME,
+			Class:     pid.Class,
+			Basis:     pid.Basis,
+			Warning:   pid.Warning,
+		})
+	}
+	return sf
+}
+
+func ffprobeToProto(m *ffmpeg_models.Metadata) *pb.FFProbeMetadata {
+	ff := &pb.FFProbeMetadata{
+		Format: &pb.FFProbeFormat{
+			Filename:       m.Format.Filename,
+			NbStreams:      int64(m.Format.NbStreams),
+			NbPrograms:     int64(m.Format.NbPrograms),
+			FormatName:     m.Format.FormatName,
+			FormatLongName: m.Format.FormatLongName,
+			Duration:       m.Format.Duration,
+			Size:           m.Format.Size,
+			BitRate:        m.Format.BitRate,
+			ProbeScore:     int64(m.Format.ProbeScore),
+			Tags:           m.Format.Tags,
+		},
+	}
+	for _, s := range m.Streams {
+		ff.Streams = append(ff.Streams, &pb.FFProbeStream{
+			Index:              int64(s.Index),
+			Id:                 s.ID,
+			CodecName:          s.CodecName,
+			CodecLongName:      s.CodecLongName,
+			Profile:            s.Profile,
+			CodecType:          s.CodecType,
+			CodecTimeBase:      s.CodecTimeBase,
+			CodecTagString:     s.CodecTagString,
+			CodecTag:           s.CodecTag,
+			Width:              int64(s.Width),
+			Height:             int64(s.Height),
+			CodedWidth:         int64(s.CodedWidth),
+			CodedHeight:        int64(s.CodedHeight),
+			SampleAspectRatio:  s.SampleAspectRatio,
+			DisplayAspectRatio: s.DisplayAspectRatio,
+			PixFmt:             s.PixFmt,
+			Level:              int64(s.Level),
+			RFrameRate:         s.RFrameRrate,
+			AvgFrameRate:       s.AvgFrameRate,
+			TimeBase:           s.TimeBase,
+			DurationTs:         int64(s.DurationTs),
+			Duration:           s.Duration,
+			BitRate:            s.BitRate,
+		})
+	}
+	return ff
+}
+
+func geometryToProto(g *indexer.Geometry) *pb.Geometry {
+	if g == nil {
+		return nil
+	}
+	return &pb.Geometry{
+		Width:  float64(g.Width),
+		Height: float64(g.Height),
+		X:      float64(g.X),
+		Y:      float64(g.Y),
+	}
+}
+
+func identifyToProto(m *indexer.FullMagickResult) (*pb.IdentifyMetadata, error) {
+	identify := &pb.IdentifyMetadata{}
+	for _, frame := range m.Frames {
+		identify.Frames = append(identify.Frames, geometryToProto(frame))
+	}
+	if m.Magick == nil {
+		return identify, nil
+	}
+	identify.Version = m.Magick.Version
+	img := m.Magick.Image
+	if img == nil {
+		return identify, nil
+	}
+	identify.Name = img.Name
+	identify.Format = img.Format
+	identify.FormatDescription = img.FormatDescription
+	identify.MimeType = img.MimeType
+	identify.Class = img.Class
+	identify.Geometry = geometryToProto(img.Geometry)
+	if img.Resolution != nil {
+		identify.ResolutionX = float64(img.Resolution.X)
+		identify.ResolutionY = float64(img.Resolution.Y)
+	}
+	identify.Units = img.Units
+	identify.Type = img.Type
+	identify.Colorspace = img.Colorspace
+	identify.Depth = int64(img.Depth)
+	identify.Pixels = int64(img.Pixels)
+	identify.Compression = img.Compression
+	identify.Quality = float64(img.Quality)
+	identify.Orientation = img.Orientation
+	if len(img.Properties) > 0 {
+		props, err := toStruct(img.Properties)
+		if err != nil {
+			return nil, err
+		}
+		identify.Properties = props
+	}
+	return identify, nil
+}
+
+// toValue converts any json serializable data to a protobuf value
+func toValue(data any) (*structpb.Value, error) {
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot marshal metadata")
+	}
+	var generic any
+	if err := json.Unmarshal(buf, &generic); err != nil {
+		return nil, errors.Wrap(err, "cannot unmarshal metadata")
+	}
+	v, err := structpb.NewValue(generic)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create protobuf value")
+	}
+	return v, nil
+}
+
+func toStruct(data map[string]any) (*structpb.Struct, error) {
+	v, err := toValue(data)
+	if err != nil {
+		return nil, err
+	}
+	return v.GetStructValue(), nil
+}
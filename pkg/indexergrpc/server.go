@@ -0,0 +1,255 @@
+// Copyright 2021 Juergen Enge, info-age GmbH, Basel. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package indexergrpc offers the indexer as grpc service
+package indexergrpc
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/je4/utils/v2/pkg/zLogger"
+	"github.com/ocfl-archive/indexer/v3/pkg/indexer"
+	pb "github.com/ocfl-archive/indexer/v3/pkg/indexerproto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type Server struct {
+	pb.UnimplementedIndexerServiceServer
+	ad            *indexer.ActionDispatcher
+	maxUploadSize int64
+	logger        zLogger.ZLogger
+}
+
+var (
+	_ pb.IndexerServiceServer = (*Server)(nil)
+)
+
+// errUploadTooLarge aborts an upload with more than maxUploadSize bytes
+var errUploadTooLarge = errors.New("upload too large")
+
+// NewServer creates the indexer service. Uploads larger than maxUploadSize are rejected, if maxUploadSize > 0.
+func NewServer(ad *indexer.ActionDispatcher, maxUploadSize int64, logger zLogger.ZLogger) *Server {
+	return &Server{
+		ad:            ad,
+		maxUploadSize: maxUploadSize,
+		logger:        logger,
+	}
+}
+
+// Register adds the indexer and the health service to the grpc server
+func (s *Server) Register(grpcServer *grpc.Server) {
+	pb.RegisterIndexerServiceServer(grpcServer, s)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(pb.IndexerService_ServiceDesc.ServiceName, grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
+}
+
+func (s *Server) ListActions(context.Context, *pb.ListActionsRequest) (*pb.ActionList, error) {
+	names := s.ad.GetActionNames()
+	slices.Sort(names)
+	list := &pb.ActionList{}
+	for _, name := range names {
+		action, ok := s.ad.GetAction(name)
+		if !ok {
+			continue
+		}
+		a := &pb.Action{
+			Name:   name,
+			Weight: uint32(action.GetWeight()),
+		}
+		for capability, str := range indexer.ACTString {
+			if action.GetCaps()&capability != 0 {
+				a.Capabilities = append(a.Capabilities, str)
+			}
+		}
+		slices.Sort(a.Capabilities)
+		list.Actions = append(list.Actions, a)
+	}
+	return list, nil
+}
+
+func (s *Server) Index(stream grpc.ClientStreamingServer[pb.IndexRequest, pb.Result]) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "cannot receive header: %v", err)
+	}
+	header := req.GetHeader()
+	if header == nil {
+		return status.Error(codes.InvalidArgument, "first message must be the header")
+	}
+	actions, err := s.getActions(header.GetActions())
+	if err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		var size int64
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				if err == io.EOF {
+					err = nil
+				}
+				pw.CloseWithError(err)
+				return
+			}
+			size += int64(len(req.GetChunk()))
+			if s.maxUploadSize > 0 && size > s.maxUploadSize {
+				pw.CloseWithError(errUploadTooLarge)
+				return
+			}
+			if _, err := pw.Write(req.GetChunk()); err != nil {
+				return
+			}
+		}
+	}()
+	result, err := s.ad.StreamContext(stream.Context(), pr, []string{header.GetFilename()}, actions)
+	// unblock the receiver, if not all data has been read
+	pr.Close()
+	if err := stream.Context().Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	if errors.Is(err, errUploadTooLarge) {
+		return status.Errorf(codes.ResourceExhausted, "upload of %s larger than %d bytes", header.GetFilename(), s.maxUploadSize)
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Msgf("cannot index %s", header.GetFilename())
+		return status.Errorf(codes.Internal, "cannot index %s: %v", header.GetFilename(), err)
+	}
+	r, err := ResultToProto(result)
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot convert result: %v", err)
+	}
+	return stream.SendAndClose(r)
+}
+
+// getActions returns the requested actions, all stream actions if none are requested.
+// Actions without ACTSTREAM need a local file and are not available.
+func (s *Server) getActions(requested []string) ([]string, error) {
+	available := s.ad.GetActionNamesByCaps(indexer.ACTSTREAM)
+	var actions []string
+	for _, name := range requested {
+		if !slices.Contains(available, name) {
+			return nil, status.Errorf(codes.InvalidArgument, "action '%s' not available", name)
+		}
+		actions = append(actions, name)
+	}
+	if len(actions) == 0 {
+		return available, nil
+	}
+	return actions, nil
+}
+
+// JWTInterceptors check the jwt from the authorization metadata of all indexer calls.
+// jwtKey is a shared secret, so only the HMAC algorithms of jwtAlg are accepted.
+func JWTInterceptors(jwtKey string, jwtAlg []string) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	check := func(ctx context.Context, method string) error {
+		if !strings.HasPrefix(method, "/"+pb.IndexerService_ServiceDesc.ServiceName+"/") {
+			return nil
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return status.Error(codes.Unauthenticated, "no token")
+		}
+		tokenStr, found := strings.CutPrefix(values[0], "Bearer ")
+		if !found {
+			return status.Error(codes.Unauthenticated, "no Bearer in authorization metadata")
+		}
+		if _, err := jwt.Parse(tokenStr, func(token *jwt.Token) (any, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, errors.Errorf("signing method %s not supported", token.Method.Alg())
+			}
+			return []byte(jwtKey), nil
+		}, jwt.WithValidMethods(jwtAlg)); err != nil {
+			return status.Errorf(codes.PermissionDenied, "invalid token: %v", err)
+		}
+		return nil
+	}
+	unary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := check(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+	stream := func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := check(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+	return unary, stream
+}
+
+// metadataCarrier adapts the grpc metadata to the trace propagator
+type metadataCarrier metadata.MD
+
+func (mc metadataCarrier) Get(key string) string {
+	values := metadata.MD(mc).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (mc metadataCarrier) Set(key, value string) {
+	metadata.MD(mc).Set(key, value)
+}
+
+func (mc metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(mc))
+	for key := range mc {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// traceStream replaces the context of a server stream
+type traceStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ts *traceStream) Context() context.Context {
+	return ts.ctx
+}
+
+// TraceInterceptors continue the trace context of the caller from the incoming metadata
+func TraceInterceptors() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	extract := func(ctx context.Context) context.Context {
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			return ctx
+		}
+		return indexer.TracePropagator().Extract(ctx, metadataCarrier(md))
+	}
+	unary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(extract(ctx), req)
+	}
+	stream := func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &traceStream{ServerStream: ss, ctx: extract(ss.Context())})
+	}
+	return unary, stream
+}
@@ -0,0 +1,142 @@
+package indexergrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/je4/utils/v2/pkg/checksum"
+	"github.com/je4/utils/v2/pkg/zLogger"
+	"github.com/ocfl-archive/indexer/v3/pkg/indexer"
+	pb "github.com/ocfl-archive/indexer/v3/pkg/indexerproto"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServer(t *testing.T) {
+	ad := indexer.NewActionDispatcher(nil)
+	indexer.NewActionChecksum("checksum", []checksum.DigestAlgorithm{checksum.DigestMD5}, ad)
+	// file only action
+	if _, err := indexer.NewActionExternal("exif", "http://localhost:1/exif/[[PATH]]", indexer.ACTFILE, indexer.EACTURL, "", nil, 0, ad); err != nil {
+		t.Fatal(err)
+	}
+	logger := zerolog.Nop()
+	var zl zLogger.ZLogger = &logger
+
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	NewServer(ad, 16, zl).Register(grpcServer)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	ctx := context.Background()
+
+	health, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, health.GetStatus())
+
+	client := pb.NewIndexerServiceClient(conn)
+	list, err := client.ListActions(ctx, &pb.ListActionsRequest{})
+	if assert.NoError(t, err) && assert.Len(t, list.GetActions(), 2) {
+		assert.Equal(t, "checksum", list.GetActions()[0].GetName())
+		assert.Contains(t, list.GetActions()[0].GetCapabilities(), "ACTSTREAM")
+		assert.Equal(t, []string{"ACTFILE"}, list.GetActions()[1].GetCapabilities())
+	}
+
+	tests := []struct {
+		name     string
+		actions  []string
+		chunks   []string
+		wantCode codes.Code
+		wantMD5  string
+	}{
+		{name: "chunks", actions: []string{"checksum"}, chunks: []string{"hello ", "world"}, wantMD5: "5eb63bbbe01eeed093cb22bb8f5acdc3"},
+		{name: "all actions", chunks: []string{"hello world"}, wantMD5: "5eb63bbbe01eeed093cb22bb8f5acdc3"},
+		{name: "unknown action", actions: []string{"unknown"}, wantCode: codes.InvalidArgument},
+		{name: "file action", actions: []string{"exif"}, wantCode: codes.InvalidArgument},
+		{name: "too large", actions: []string{"checksum"}, chunks: []string{"hello world", "hello world"}, wantCode: codes.ResourceExhausted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := client.Index(ctx)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.NoError(t, stream.Send(&pb.IndexRequest{Data: &pb.IndexRequest_Header{Header: &pb.IndexHeader{Filename: "test.txt", Actions: tt.actions}}}))
+			for _, chunk := range tt.chunks {
+				assert.NoError(t, stream.Send(&pb.IndexRequest{Data: &pb.IndexRequest_Chunk{Chunk: []byte(chunk)}}))
+			}
+			result, err := stream.CloseAndRecv()
+			if tt.wantCode != codes.OK {
+				assert.Equal(t, tt.wantCode, status.Code(err))
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantMD5, result.GetMetadata()["checksum"].GetChecksum().GetChecksums()[string(checksum.DigestMD5)])
+		})
+	}
+}
+
+func TestTraceInterceptors(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	ad := indexer.NewActionDispatcher(nil)
+	ad.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	indexer.NewActionChecksum("checksum", []checksum.DigestAlgorithm{checksum.DigestMD5}, ad)
+	logger := zerolog.Nop()
+
+	listener := bufconn.Listen(1024 * 1024)
+	unary, streamInterceptor := TraceInterceptors()
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(unary), grpc.StreamInterceptor(streamInterceptor))
+	NewServer(ad, 0, &logger).Register(grpcServer)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	const traceID, spanID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "traceparent", "00-"+traceID+"-"+spanID+"-01")
+	stream, err := pb.NewIndexerServiceClient(conn).Index(ctx)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, stream.Send(&pb.IndexRequest{Data: &pb.IndexRequest_Header{Header: &pb.IndexHeader{Filename: "test.txt"}}}))
+	assert.NoError(t, stream.Send(&pb.IndexRequest{Data: &pb.IndexRequest_Chunk{Chunk: []byte("hello world")}}))
+	_, err = stream.CloseAndRecv()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	spans := exporter.GetSpans()
+	if assert.NotEmpty(t, spans) {
+		root := spans[len(spans)-1]
+		assert.Equal(t, "Stream", root.Name)
+		assert.Equal(t, traceID, root.SpanContext.TraceID().String())
+		assert.Equal(t, spanID, root.Parent.SpanID().String())
+	}
+}
@@ -0,0 +1,1832 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: indexer.proto
+
+package indexerproto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type IndexHeader struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Filename string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	// without actions, all configured actions are used
+	Actions       []string `protobuf:"bytes,2,rep,name=actions,proto3" json:"actions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndexHeader) Reset() {
+	*x = IndexHeader{}
+	mi := &file_indexer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndexHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndexHeader) ProtoMessage() {}
+
+func (x *IndexHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndexHeader.ProtoReflect.Descriptor instead.
+func (*IndexHeader) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *IndexHeader) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *IndexHeader) GetActions() []string {
+	if x != nil {
+		return x.Actions
+	}
+	return nil
+}
+
+type IndexRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Data:
+	//
+	//	*IndexRequest_Header
+	//	*IndexRequest_Chunk
+	Data          isIndexRequest_Data `protobuf_oneof:"data"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndexRequest) Reset() {
+	*x = IndexRequest{}
+	mi := &file_indexer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndexRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndexRequest) ProtoMessage() {}
+
+func (x *IndexRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndexRequest.ProtoReflect.Descriptor instead.
+func (*IndexRequest) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *IndexRequest) GetData() isIndexRequest_Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *IndexRequest) GetHeader() *IndexHeader {
+	if x != nil {
+		if x, ok := x.Data.(*IndexRequest_Header); ok {
+			return x.Header
+		}
+	}
+	return nil
+}
+
+func (x *IndexRequest) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Data.(*IndexRequest_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isIndexRequest_Data interface {
+	isIndexRequest_Data()
+}
+
+type IndexRequest_Header struct {
+	// first message of the stream
+	Header *IndexHeader `protobuf:"bytes,1,opt,name=header,proto3,oneof"`
+}
+
+type IndexRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*IndexRequest_Header) isIndexRequest_Data() {}
+
+func (*IndexRequest_Chunk) isIndexRequest_Data() {}
+
+type ListActionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListActionsRequest) Reset() {
+	*x = ListActionsRequest{}
+	mi := &file_indexer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListActionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListActionsRequest) ProtoMessage() {}
+
+func (x *ListActionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListActionsRequest.ProtoReflect.Descriptor instead.
+func (*ListActionsRequest) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{2}
+}
+
+type Action struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Capabilities  []string               `protobuf:"bytes,2,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	Weight        uint32                 `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Action) Reset() {
+	*x = Action{}
+	mi := &file_indexer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Action) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Action) ProtoMessage() {}
+
+func (x *Action) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Action.ProtoReflect.Descriptor instead.
+func (*Action) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Action) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Action) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *Action) GetWeight() uint32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+type ActionList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Actions       []*Action              `protobuf:"bytes,1,rep,name=actions,proto3" json:"actions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ActionList) Reset() {
+	*x = ActionList{}
+	mi := &file_indexer_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActionList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActionList) ProtoMessage() {}
+
+func (x *ActionList) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActionList.ProtoReflect.Descriptor instead.
+func (*ActionList) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ActionList) GetActions() []*Action {
+	if x != nil {
+		return x.Actions
+	}
+	return nil
+}
+
+type Result struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Errors    map[string]string      `protobuf:"bytes,1,rep,name=errors,proto3" json:"errors,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Mimetype  string                 `protobuf:"bytes,2,opt,name=mimetype,proto3" json:"mimetype,omitempty"`
+	Mimetypes []string               `protobuf:"bytes,3,rep,name=mimetypes,proto3" json:"mimetypes,omitempty"`
+	Pronom    string                 `protobuf:"bytes,4,opt,name=pronom,proto3" json:"pronom,omitempty"`
+	Pronoms   []string               `protobuf:"bytes,5,rep,name=pronoms,proto3" json:"pronoms,omitempty"`
+	Checksum  map[string]string      `protobuf:"bytes,6,rep,name=checksum,proto3" json:"checksum,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Width     uint64                 `protobuf:"varint,7,opt,name=width,proto3" json:"width,omitempty"`
+	Height    uint64                 `protobuf:"varint,8,opt,name=height,proto3" json:"height,omitempty"`
+	Duration  uint64                 `protobuf:"varint,9,opt,name=duration,proto3" json:"duration,omitempty"`
+	Size      uint64                 `protobuf:"varint,10,opt,name=size,proto3" json:"size,omitempty"`
+	Metadata  map[string]*Metadata   `protobuf:"bytes,11,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Type      string                 `protobuf:"bytes,12,opt,name=type,proto3" json:"type,omitempty"`
+	Subtype   string                 `protobuf:"bytes,13,opt,name=subtype,proto3" json:"subtype,omitempty"`
+	// only the head of the data has been indexed
+	Partial       bool           `protobuf:"varint,14,opt,name=partial,proto3" json:"partial,omitempty"`
+	ActionErrors  []*ActionError `protobuf:"bytes,15,rep,name=actionErrors,proto3" json:"actionErrors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Result) Reset() {
+	*x = Result{}
+	mi := &file_indexer_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Result) ProtoMessage() {}
+
+func (x *Result) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Result.ProtoReflect.Descriptor instead.
+func (*Result) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Result) GetErrors() map[string]string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *Result) GetMimetype() string {
+	if x != nil {
+		return x.Mimetype
+	}
+	return ""
+}
+
+func (x *Result) GetMimetypes() []string {
+	if x != nil {
+		return x.Mimetypes
+	}
+	return nil
+}
+
+func (x *Result) GetPronom() string {
+	if x != nil {
+		return x.Pronom
+	}
+	return ""
+}
+
+func (x *Result) GetPronoms() []string {
+	if x != nil {
+		return x.Pronoms
+	}
+	return nil
+}
+
+func (x *Result) GetChecksum() map[string]string {
+	if x != nil {
+		return x.Checksum
+	}
+	return nil
+}
+
+func (x *Result) GetWidth() uint64 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Result) GetHeight() uint64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Result) GetDuration() uint64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *Result) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Result) GetMetadata() map[string]*Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Result) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Result) GetSubtype() string {
+	if x != nil {
+		return x.Subtype
+	}
+	return ""
+}
+
+func (x *Result) GetPartial() bool {
+	if x != nil {
+		return x.Partial
+	}
+	return false
+}
+
+func (x *Result) GetActionErrors() []*ActionError {
+	if x != nil {
+		return x.ActionErrors
+	}
+	return nil
+}
+
+// structured error of a failed action, the ids are registered in errors.toml
+type ActionError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Severity      int64                  `protobuf:"varint,4,opt,name=severity,proto3" json:"severity,omitempty"`
+	Retriable     bool                   `protobuf:"varint,5,opt,name=retriable,proto3" json:"retriable,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ActionError) Reset() {
+	*x = ActionError{}
+	mi := &file_indexer_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActionError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActionError) ProtoMessage() {}
+
+func (x *ActionError) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActionError.ProtoReflect.Descriptor instead.
+func (*ActionError) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ActionError) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ActionError) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ActionError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ActionError) GetSeverity() int64 {
+	if x != nil {
+		return x.Severity
+	}
+	return 0
+}
+
+func (x *ActionError) GetRetriable() bool {
+	if x != nil {
+		return x.Retriable
+	}
+	return false
+}
+
+// Metadata of one action, typed for the known actions
+type Metadata struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Metadata:
+	//
+	//	*Metadata_Siegfried
+	//	*Metadata_Ffprobe
+	//	*Metadata_Identify
+	//	*Metadata_Tika
+	//	*Metadata_Checksum
+	//	*Metadata_Other
+	Metadata      isMetadata_Metadata `protobuf_oneof:"metadata"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Metadata) Reset() {
+	*x = Metadata{}
+	mi := &file_indexer_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Metadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Metadata) ProtoMessage() {}
+
+func (x *Metadata) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Metadata.ProtoReflect.Descriptor instead.
+func (*Metadata) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Metadata) GetMetadata() isMetadata_Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Metadata) GetSiegfried() *SiegfriedMetadata {
+	if x != nil {
+		if x, ok := x.Metadata.(*Metadata_Siegfried); ok {
+			return x.Siegfried
+		}
+	}
+	return nil
+}
+
+func (x *Metadata) GetFfprobe() *FFProbeMetadata {
+	if x != nil {
+		if x, ok := x.Metadata.(*Metadata_Ffprobe); ok {
+			return x.Ffprobe
+		}
+	}
+	return nil
+}
+
+func (x *Metadata) GetIdentify() *IdentifyMetadata {
+	if x != nil {
+		if x, ok := x.Metadata.(*Metadata_Identify); ok {
+			return x.Identify
+		}
+	}
+	return nil
+}
+
+func (x *Metadata) GetTika() *TikaMetadata {
+	if x != nil {
+		if x, ok := x.Metadata.(*Metadata_Tika); ok {
+			return x.Tika
+		}
+	}
+	return nil
+}
+
+func (x *Metadata) GetChecksum() *ChecksumMetadata {
+	if x != nil {
+		if x, ok := x.Metadata.(*Metadata_Checksum); ok {
+			return x.Checksum
+		}
+	}
+	return nil
+}
+
+func (x *Metadata) GetOther() *structpb.Value {
+	if x != nil {
+		if x, ok := x.Metadata.(*Metadata_Other); ok {
+			return x.Other
+		}
+	}
+	return nil
+}
+
+type isMetadata_Metadata interface {
+	isMetadata_Metadata()
+}
+
+type Metadata_Siegfried struct {
+	Siegfried *SiegfriedMetadata `protobuf:"bytes,1,opt,name=siegfried,proto3,oneof"`
+}
+
+type Metadata_Ffprobe struct {
+	Ffprobe *FFProbeMetadata `protobuf:"bytes,2,opt,name=ffprobe,proto3,oneof"`
+}
+
+type Metadata_Identify struct {
+	Identify *IdentifyMetadata `protobuf:"bytes,3,opt,name=identify,proto3,oneof"`
+}
+
+type Metadata_Tika struct {
+	Tika *TikaMetadata `protobuf:"bytes,4,opt,name=tika,proto3,oneof"`
+}
+
+type Metadata_Checksum struct {
+	Checksum *ChecksumMetadata `protobuf:"bytes,5,opt,name=checksum,proto3,oneof"`
+}
+
+type Metadata_Other struct {
+	// metadata of all other actions
+	Other *structpb.Value `protobuf:"bytes,6,opt,name=other,proto3,oneof"`
+}
+
+func (*Metadata_Siegfried) isMetadata_Metadata() {}
+
+func (*Metadata_Ffprobe) isMetadata_Metadata() {}
+
+func (*Metadata_Identify) isMetadata_Metadata() {}
+
+func (*Metadata_Tika) isMetadata_Metadata() {}
+
+func (*Metadata_Checksum) isMetadata_Metadata() {}
+
+func (*Metadata_Other) isMetadata_Metadata() {}
+
+type SiegfriedIdentification struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Version       string                 `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	Mime          string                 `protobuf:"bytes,5,opt,name=mime,proto3" json:"mime,omitempty"`
+	Class         string                 `protobuf:"bytes,6,opt,name=class,proto3" json:"class,omitempty"`
+	Basis         []string               `protobuf:"bytes,7,rep,name=basis,proto3" json:"basis,omitempty"`
+	Warning       string                 `protobuf:"bytes,8,opt,name=warning,proto3" json:"warning,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SiegfriedIdentification) Reset() {
+	*x = SiegfriedIdentification{}
+	mi := &file_indexer_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SiegfriedIdentification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SiegfriedIdentification) ProtoMessage() {}
+
+func (x *SiegfriedIdentification) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SiegfriedIdentification.ProtoReflect.Descriptor instead.
+func (*SiegfriedIdentification) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SiegfriedIdentification) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *SiegfriedIdentification) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SiegfriedIdentification) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SiegfriedIdentification) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *SiegfriedIdentification) GetMime() string {
+	if x != nil {
+		return x.Mime
+	}
+	return ""
+}
+
+func (x *SiegfriedIdentification) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+func (x *SiegfriedIdentification) GetBasis() []string {
+	if x != nil {
+		return x.Basis
+	}
+	return nil
+}
+
+func (x *SiegfriedIdentification) GetWarning() string {
+	if x != nil {
+		return x.Warning
+	}
+	return ""
+}
+
+type SiegfriedMetadata struct {
+	state           protoimpl.MessageState     `protogen:"open.v1"`
+	Identifications []*SiegfriedIdentification `protobuf:"bytes,1,rep,name=identifications,proto3" json:"identifications,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SiegfriedMetadata) Reset() {
+	*x = SiegfriedMetadata{}
+	mi := &file_indexer_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SiegfriedMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SiegfriedMetadata) ProtoMessage() {}
+
+func (x *SiegfriedMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SiegfriedMetadata.ProtoReflect.Descriptor instead.
+func (*SiegfriedMetadata) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SiegfriedMetadata) GetIdentifications() []*SiegfriedIdentification {
+	if x != nil {
+		return x.Identifications
+	}
+	return nil
+}
+
+type FFProbeFormat struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Filename       string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	NbStreams      int64                  `protobuf:"varint,2,opt,name=nb_streams,json=nbStreams,proto3" json:"nb_streams,omitempty"`
+	NbPrograms     int64                  `protobuf:"varint,3,opt,name=nb_programs,json=nbPrograms,proto3" json:"nb_programs,omitempty"`
+	FormatName     string                 `protobuf:"bytes,4,opt,name=format_name,json=formatName,proto3" json:"format_name,omitempty"`
+	FormatLongName string                 `protobuf:"bytes,5,opt,name=format_long_name,json=formatLongName,proto3" json:"format_long_name,omitempty"`
+	Duration       string                 `protobuf:"bytes,6,opt,name=duration,proto3" json:"duration,omitempty"`
+	Size           string                 `protobuf:"bytes,7,opt,name=size,proto3" json:"size,omitempty"`
+	BitRate        string                 `protobuf:"bytes,8,opt,name=bit_rate,json=bitRate,proto3" json:"bit_rate,omitempty"`
+	ProbeScore     int64                  `protobuf:"varint,9,opt,name=probe_score,json=probeScore,proto3" json:"probe_score,omitempty"`
+	Tags           map[string]string      `protobuf:"bytes,10,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *FFProbeFormat) Reset() {
+	*x = FFProbeFormat{}
+	mi := &file_indexer_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FFProbeFormat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FFProbeFormat) ProtoMessage() {}
+
+func (x *FFProbeFormat) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FFProbeFormat.ProtoReflect.Descriptor instead.
+func (*FFProbeFormat) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *FFProbeFormat) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *FFProbeFormat) GetNbStreams() int64 {
+	if x != nil {
+		return x.NbStreams
+	}
+	return 0
+}
+
+func (x *FFProbeFormat) GetNbPrograms() int64 {
+	if x != nil {
+		return x.NbPrograms
+	}
+	return 0
+}
+
+func (x *FFProbeFormat) GetFormatName() string {
+	if x != nil {
+		return x.FormatName
+	}
+	return ""
+}
+
+func (x *FFProbeFormat) GetFormatLongName() string {
+	if x != nil {
+		return x.FormatLongName
+	}
+	return ""
+}
+
+func (x *FFProbeFormat) GetDuration() string {
+	if x != nil {
+		return x.Duration
+	}
+	return ""
+}
+
+func (x *FFProbeFormat) GetSize() string {
+	if x != nil {
+		return x.Size
+	}
+	return ""
+}
+
+func (x *FFProbeFormat) GetBitRate() string {
+	if x != nil {
+		return x.BitRate
+	}
+	return ""
+}
+
+func (x *FFProbeFormat) GetProbeScore() int64 {
+	if x != nil {
+		return x.ProbeScore
+	}
+	return 0
+}
+
+func (x *FFProbeFormat) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type FFProbeStream struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Index              int64                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Id                 string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	CodecName          string                 `protobuf:"bytes,3,opt,name=codec_name,json=codecName,proto3" json:"codec_name,omitempty"`
+	CodecLongName      string                 `protobuf:"bytes,4,opt,name=codec_long_name,json=codecLongName,proto3" json:"codec_long_name,omitempty"`
+	Profile            string                 `protobuf:"bytes,5,opt,name=profile,proto3" json:"profile,omitempty"`
+	CodecType          string                 `protobuf:"bytes,6,opt,name=codec_type,json=codecType,proto3" json:"codec_type,omitempty"`
+	CodecTimeBase      string                 `protobuf:"bytes,7,opt,name=codec_time_base,json=codecTimeBase,proto3" json:"codec_time_base,omitempty"`
+	CodecTagString     string                 `protobuf:"bytes,8,opt,name=codec_tag_string,json=codecTagString,proto3" json:"codec_tag_string,omitempty"`
+	CodecTag           string                 `protobuf:"bytes,9,opt,name=codec_tag,json=codecTag,proto3" json:"codec_tag,omitempty"`
+	Width              int64                  `protobuf:"varint,10,opt,name=width,proto3" json:"width,omitempty"`
+	Height             int64                  `protobuf:"varint,11,opt,name=height,proto3" json:"height,omitempty"`
+	CodedWidth         int64                  `protobuf:"varint,12,opt,name=coded_width,json=codedWidth,proto3" json:"coded_width,omitempty"`
+	CodedHeight        int64                  `protobuf:"varint,13,opt,name=coded_height,json=codedHeight,proto3" json:"coded_height,omitempty"`
+	SampleAspectRatio  string                 `protobuf:"bytes,14,opt,name=sample_aspect_ratio,json=sampleAspectRatio,proto3" json:"sample_aspect_ratio,omitempty"`
+	DisplayAspectRatio string                 `protobuf:"bytes,15,opt,name=display_aspect_ratio,json=displayAspectRatio,proto3" json:"display_aspect_ratio,omitempty"`
+	PixFmt             string                 `protobuf:"bytes,16,opt,name=pix_fmt,json=pixFmt,proto3" json:"pix_fmt,omitempty"`
+	Level              int64                  `protobuf:"varint,17,opt,name=level,proto3" json:"level,omitempty"`
+	RFrameRate         string                 `protobuf:"bytes,18,opt,name=r_frame_rate,json=rFrameRate,proto3" json:"r_frame_rate,omitempty"`
+	AvgFrameRate       string                 `protobuf:"bytes,19,opt,name=avg_frame_rate,json=avgFrameRate,proto3" json:"avg_frame_rate,omitempty"`
+	TimeBase           string                 `protobuf:"bytes,20,opt,name=time_base,json=timeBase,proto3" json:"time_base,omitempty"`
+	DurationTs         int64                  `protobuf:"varint,21,opt,name=duration_ts,json=durationTs,proto3" json:"duration_ts,omitempty"`
+	Duration           string                 `protobuf:"bytes,22,opt,name=duration,proto3" json:"duration,omitempty"`
+	BitRate            string                 `protobuf:"bytes,23,opt,name=bit_rate,json=bitRate,proto3" json:"bit_rate,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *FFProbeStream) Reset() {
+	*x = FFProbeStream{}
+	mi := &file_indexer_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FFProbeStream) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FFProbeStream) ProtoMessage() {}
+
+func (x *FFProbeStream) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FFProbeStream.ProtoReflect.Descriptor instead.
+func (*FFProbeStream) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FFProbeStream) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *FFProbeStream) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *FFProbeStream) GetCodecName() string {
+	if x != nil {
+		return x.CodecName
+	}
+	return ""
+}
+
+func (x *FFProbeStream) GetCodecLongName() string {
+	if x != nil {
+		return x.CodecLongName
+	}
+	return ""
+}
+
+func (x *FFProbeStream) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+func (x *FFProbeStream) GetCodecType() string {
+	if x != nil {
+		return x.CodecType
+	}
+	return ""
+}
+
+func (x *FFProbeStream) GetCodecTimeBase() string {
+	if x != nil {
+		return x.CodecTimeBase
+	}
+	return ""
+}
+
+func (x *FFProbeStream) GetCodecTagString() string {
+	if x != nil {
+		return x.CodecTagString
+	}
+	return ""
+}
+
+func (x *FFProbeStream) GetCodecTag() string {
+	if x != nil {
+		return x.CodecTag
+	}
+	return ""
+}
+
+func (x *FFProbeStream) GetWidth() int64 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *FFProbeStream) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *FFProbeStream) GetCodedWidth() int64 {
+	if x != nil {
+		return x.CodedWidth
+	}
+	return 0
+}
+
+func (x *FFProbeStream) GetCodedHeight() int64 {
+	if x != nil {
+		return x.CodedHeight
+	}
+	return 0
+}
+
+func (x *FFProbeStream) GetSampleAspectRatio() string {
+	if x != nil {
+		return x.SampleAspectRatio
+	}
+	return ""
+}
+
+func (x *FFProbeStream) GetDisplayAspectRatio() string {
+	if x != nil {
+		return x.DisplayAspectRatio
+	}
+	return ""
+}
+
+func (x *FFProbeStream) GetPixFmt() string {
+	if x != nil {
+		return x.PixFmt
+	}
+	return ""
+}
+
+func (x *FFProbeStream) GetLevel() int64 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *FFProbeStream) GetRFrameRate() string {
+	if x != nil {
+		return x.RFrameRate
+	}
+	return ""
+}
+
+func (x *FFProbeStream) GetAvgFrameRate() string {
+	if x != nil {
+		return x.AvgFrameRate
+	}
+	return ""
+}
+
+func (x *FFProbeStream) GetTimeBase() string {
+	if x != nil {
+		return x.TimeBase
+	}
+	return ""
+}
+
+func (x *FFProbeStream) GetDurationTs() int64 {
+	if x != nil {
+		return x.DurationTs
+	}
+	return 0
+}
+
+func (x *FFProbeStream) GetDuration() string {
+	if x != nil {
+		return x.Duration
+	}
+	return ""
+}
+
+func (x *FFProbeStream) GetBitRate() string {
+	if x != nil {
+		return x.BitRate
+	}
+	return ""
+}
+
+type FFProbeMetadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Format        *FFProbeFormat         `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	Streams       []*FFProbeStream       `protobuf:"bytes,2,rep,name=streams,proto3" json:"streams,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FFProbeMetadata) Reset() {
+	*x = FFProbeMetadata{}
+	mi := &file_indexer_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FFProbeMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FFProbeMetadata) ProtoMessage() {}
+
+func (x *FFProbeMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FFProbeMetadata.ProtoReflect.Descriptor instead.
+func (*FFProbeMetadata) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *FFProbeMetadata) GetFormat() *FFProbeFormat {
+	if x != nil {
+		return x.Format
+	}
+	return nil
+}
+
+func (x *FFProbeMetadata) GetStreams() []*FFProbeStream {
+	if x != nil {
+		return x.Streams
+	}
+	return nil
+}
+
+type Geometry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Width         float64                `protobuf:"fixed64,1,opt,name=width,proto3" json:"width,omitempty"`
+	Height        float64                `protobuf:"fixed64,2,opt,name=height,proto3" json:"height,omitempty"`
+	X             float64                `protobuf:"fixed64,3,opt,name=x,proto3" json:"x,omitempty"`
+	Y             float64                `protobuf:"fixed64,4,opt,name=y,proto3" json:"y,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Geometry) Reset() {
+	*x = Geometry{}
+	mi := &file_indexer_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Geometry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Geometry) ProtoMessage() {}
+
+func (x *Geometry) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Geometry.ProtoReflect.Descriptor instead.
+func (*Geometry) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Geometry) GetWidth() float64 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Geometry) GetHeight() float64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Geometry) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Geometry) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+type IdentifyMetadata struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Version           string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Name              string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Format            string                 `protobuf:"bytes,3,opt,name=format,proto3" json:"format,omitempty"`
+	FormatDescription string                 `protobuf:"bytes,4,opt,name=format_description,json=formatDescription,proto3" json:"format_description,omitempty"`
+	MimeType          string                 `protobuf:"bytes,5,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	Class             string                 `protobuf:"bytes,6,opt,name=class,proto3" json:"class,omitempty"`
+	Geometry          *Geometry              `protobuf:"bytes,7,opt,name=geometry,proto3" json:"geometry,omitempty"`
+	ResolutionX       float64                `protobuf:"fixed64,8,opt,name=resolution_x,json=resolutionX,proto3" json:"resolution_x,omitempty"`
+	ResolutionY       float64                `protobuf:"fixed64,9,opt,name=resolution_y,json=resolutionY,proto3" json:"resolution_y,omitempty"`
+	Units             string                 `protobuf:"bytes,10,opt,name=units,proto3" json:"units,omitempty"`
+	Type              string                 `protobuf:"bytes,11,opt,name=type,proto3" json:"type,omitempty"`
+	Colorspace        string                 `protobuf:"bytes,12,opt,name=colorspace,proto3" json:"colorspace,omitempty"`
+	Depth             int64                  `protobuf:"varint,13,opt,name=depth,proto3" json:"depth,omitempty"`
+	Pixels            int64                  `protobuf:"varint,14,opt,name=pixels,proto3" json:"pixels,omitempty"`
+	Compression       string                 `protobuf:"bytes,15,opt,name=compression,proto3" json:"compression,omitempty"`
+	Quality           float64                `protobuf:"fixed64,16,opt,name=quality,proto3" json:"quality,omitempty"`
+	Orientation       string                 `protobuf:"bytes,17,opt,name=orientation,proto3" json:"orientation,omitempty"`
+	Properties        *structpb.Struct       `protobuf:"bytes,18,opt,name=properties,proto3" json:"properties,omitempty"`
+	Frames            []*Geometry            `protobuf:"bytes,19,rep,name=frames,proto3" json:"frames,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *IdentifyMetadata) Reset() {
+	*x = IdentifyMetadata{}
+	mi := &file_indexer_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IdentifyMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IdentifyMetadata) ProtoMessage() {}
+
+func (x *IdentifyMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IdentifyMetadata.ProtoReflect.Descriptor instead.
+func (*IdentifyMetadata) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *IdentifyMetadata) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *IdentifyMetadata) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *IdentifyMetadata) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *IdentifyMetadata) GetFormatDescription() string {
+	if x != nil {
+		return x.FormatDescription
+	}
+	return ""
+}
+
+func (x *IdentifyMetadata) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *IdentifyMetadata) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+func (x *IdentifyMetadata) GetGeometry() *Geometry {
+	if x != nil {
+		return x.Geometry
+	}
+	return nil
+}
+
+func (x *IdentifyMetadata) GetResolutionX() float64 {
+	if x != nil {
+		return x.ResolutionX
+	}
+	return 0
+}
+
+func (x *IdentifyMetadata) GetResolutionY() float64 {
+	if x != nil {
+		return x.ResolutionY
+	}
+	return 0
+}
+
+func (x *IdentifyMetadata) GetUnits() string {
+	if x != nil {
+		return x.Units
+	}
+	return ""
+}
+
+func (x *IdentifyMetadata) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *IdentifyMetadata) GetColorspace() string {
+	if x != nil {
+		return x.Colorspace
+	}
+	return ""
+}
+
+func (x *IdentifyMetadata) GetDepth() int64 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *IdentifyMetadata) GetPixels() int64 {
+	if x != nil {
+		return x.Pixels
+	}
+	return 0
+}
+
+func (x *IdentifyMetadata) GetCompression() string {
+	if x != nil {
+		return x.Compression
+	}
+	return ""
+}
+
+func (x *IdentifyMetadata) GetQuality() float64 {
+	if x != nil {
+		return x.Quality
+	}
+	return 0
+}
+
+func (x *IdentifyMetadata) GetOrientation() string {
+	if x != nil {
+		return x.Orientation
+	}
+	return ""
+}
+
+func (x *IdentifyMetadata) GetProperties() *structpb.Struct {
+	if x != nil {
+		return x.Properties
+	}
+	return nil
+}
+
+func (x *IdentifyMetadata) GetFrames() []*Geometry {
+	if x != nil {
+		return x.Frames
+	}
+	return nil
+}
+
+type TikaMetadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Documents     []*structpb.Struct     `protobuf:"bytes,1,rep,name=documents,proto3" json:"documents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TikaMetadata) Reset() {
+	*x = TikaMetadata{}
+	mi := &file_indexer_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TikaMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TikaMetadata) ProtoMessage() {}
+
+func (x *TikaMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TikaMetadata.ProtoReflect.Descriptor instead.
+func (*TikaMetadata) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *TikaMetadata) GetDocuments() []*structpb.Struct {
+	if x != nil {
+		return x.Documents
+	}
+	return nil
+}
+
+type ChecksumMetadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Checksums     map[string]string      `protobuf:"bytes,1,rep,name=checksums,proto3" json:"checksums,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChecksumMetadata) Reset() {
+	*x = ChecksumMetadata{}
+	mi := &file_indexer_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChecksumMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChecksumMetadata) ProtoMessage() {}
+
+func (x *ChecksumMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChecksumMetadata.ProtoReflect.Descriptor instead.
+func (*ChecksumMetadata) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ChecksumMetadata) GetChecksums() map[string]string {
+	if x != nil {
+		return x.Checksums
+	}
+	return nil
+}
+
+var File_indexer_proto protoreflect.FileDescriptor
+
+const file_indexer_proto_rawDesc = "" +
+	"\n" +
+	"\rindexer.proto\x12\n" +
+	"indexer.v3\x1a\x1cgoogle/protobuf/struct.proto\"C\n" +
+	"\vIndexHeader\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x18\n" +
+	"\aactions\x18\x02 \x03(\tR\aactions\"a\n" +
+	"\fIndexRequest\x121\n" +
+	"\x06header\x18\x01 \x01(\v2\x17.indexer.v3.IndexHeaderH\x00R\x06header\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\x06\n" +
+	"\x04data\"\x14\n" +
+	"\x12ListActionsRequest\"X\n" +
+	"\x06Action\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\"\n" +
+	"\fcapabilities\x18\x02 \x03(\tR\fcapabilities\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\rR\x06weight\":\n" +
+	"\n" +
+	"ActionList\x12,\n" +
+	"\aactions\x18\x01 \x03(\v2\x12.indexer.v3.ActionR\aactions\"\xd6\x05\n" +
+	"\x06Result\x126\n" +
+	"\x06errors\x18\x01 \x03(\v2\x1e.indexer.v3.Result.ErrorsEntryR\x06errors\x12\x1a\n" +
+	"\bmimetype\x18\x02 \x01(\tR\bmimetype\x12\x1c\n" +
+	"\tmimetypes\x18\x03 \x03(\tR\tmimetypes\x12\x16\n" +
+	"\x06pronom\x18\x04 \x01(\tR\x06pronom\x12\x18\n" +
+	"\apronoms\x18\x05 \x03(\tR\apronoms\x12<\n" +
+	"\bchecksum\x18\x06 \x03(\v2 .indexer.v3.Result.ChecksumEntryR\bchecksum\x12\x14\n" +
+	"\x05width\x18\a \x01(\x04R\x05width\x12\x16\n" +
+	"\x06height\x18\b \x01(\x04R\x06height\x12\x1a\n" +
+	"\bduration\x18\t \x01(\x04R\bduration\x12\x12\n" +
+	"\x04size\x18\n" +
+	" \x01(\x04R\x04size\x12<\n" +
+	"\bmetadata\x18\v \x03(\v2 .indexer.v3.Result.MetadataEntryR\bmetadata\x12\x12\n" +
+	"\x04type\x18\f \x01(\tR\x04type\x12\x18\n" +
+	"\asubtype\x18\r \x01(\tR\asubtype\x12\x18\n" +
+	"\apartial\x18\x0e \x01(\bR\apartial\x12;\n" +
+	"\factionErrors\x18\x0f \x03(\v2\x17.indexer.v3.ActionErrorR\factionErrors\x1a9\n" +
+	"\vErrorsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a;\n" +
+	"\rChecksumEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aQ\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12*\n" +
+	"\x05value\x18\x02 \x01(\v2\x14.indexer.v3.MetadataR\x05value:\x028\x01\"\x89\x01\n" +
+	"\vActionError\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x1a\n" +
+	"\bseverity\x18\x04 \x01(\x03R\bseverity\x12\x1c\n" +
+	"\tretriable\x18\x05 \x01(\bR\tretriable\"\xe6\x02\n" +
+	"\bMetadata\x12=\n" +
+	"\tsiegfried\x18\x01 \x01(\v2\x1d.indexer.v3.SiegfriedMetadataH\x00R\tsiegfried\x127\n" +
+	"\affprobe\x18\x02 \x01(\v2\x1b.indexer.v3.FFProbeMetadataH\x00R\affprobe\x12:\n" +
+	"\bidentify\x18\x03 \x01(\v2\x1c.indexer.v3.IdentifyMetadataH\x00R\bidentify\x12.\n" +
+	"\x04tika\x18\x04 \x01(\v2\x18.indexer.v3.TikaMetadataH\x00R\x04tika\x12:\n" +
+	"\bchecksum\x18\x05 \x01(\v2\x1c.indexer.v3.ChecksumMetadataH\x00R\bchecksum\x12.\n" +
+	"\x05other\x18\x06 \x01(\v2\x16.google.protobuf.ValueH\x00R\x05otherB\n" +
+	"\n" +
+	"\bmetadata\"\xcf\x01\n" +
+	"\x17SiegfriedIdentification\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\tR\aversion\x12\x12\n" +
+	"\x04mime\x18\x05 \x01(\tR\x04mime\x12\x14\n" +
+	"\x05class\x18\x06 \x01(\tR\x05class\x12\x14\n" +
+	"\x05basis\x18\a \x03(\tR\x05basis\x12\x18\n" +
+	"\awarning\x18\b \x01(\tR\awarning\"b\n" +
+	"\x11SiegfriedMetadata\x12M\n" +
+	"\x0fidentifications\x18\x01 \x03(\v2#.indexer.v3.SiegfriedIdentificationR\x0fidentifications\"\x94\x03\n" +
+	"\rFFProbeFormat\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x1d\n" +
+	"\n" +
+	"nb_streams\x18\x02 \x01(\x03R\tnbStreams\x12\x1f\n" +
+	"\vnb_programs\x18\x03 \x01(\x03R\n" +
+	"nbPrograms\x12\x1f\n" +
+	"\vformat_name\x18\x04 \x01(\tR\n" +
+	"formatName\x12(\n" +
+	"\x10format_long_name\x18\x05 \x01(\tR\x0eformatLongName\x12\x1a\n" +
+	"\bduration\x18\x06 \x01(\tR\bduration\x12\x12\n" +
+	"\x04size\x18\a \x01(\tR\x04size\x12\x19\n" +
+	"\bbit_rate\x18\b \x01(\tR\abitRate\x12\x1f\n" +
+	"\vprobe_score\x18\t \x01(\x03R\n" +
+	"probeScore\x127\n" +
+	"\x04tags\x18\n" +
+	" \x03(\v2#.indexer.v3.FFProbeFormat.TagsEntryR\x04tags\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe4\x05\n" +
+	"\rFFProbeStream\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x03R\x05index\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"codec_name\x18\x03 \x01(\tR\tcodecName\x12&\n" +
+	"\x0fcodec_long_name\x18\x04 \x01(\tR\rcodecLongName\x12\x18\n" +
+	"\aprofile\x18\x05 \x01(\tR\aprofile\x12\x1d\n" +
+	"\n" +
+	"codec_type\x18\x06 \x01(\tR\tcodecType\x12&\n" +
+	"\x0fcodec_time_base\x18\a \x01(\tR\rcodecTimeBase\x12(\n" +
+	"\x10codec_tag_string\x18\b \x01(\tR\x0ecodecTagString\x12\x1b\n" +
+	"\tcodec_tag\x18\t \x01(\tR\bcodecTag\x12\x14\n" +
+	"\x05width\x18\n" +
+	" \x01(\x03R\x05width\x12\x16\n" +
+	"\x06height\x18\v \x01(\x03R\x06height\x12\x1f\n" +
+	"\vcoded_width\x18\f \x01(\x03R\n" +
+	"codedWidth\x12!\n" +
+	"\fcoded_height\x18\r \x01(\x03R\vcodedHeight\x12.\n" +
+	"\x13sample_aspect_ratio\x18\x0e \x01(\tR\x11sampleAspectRatio\x120\n" +
+	"\x14display_aspect_ratio\x18\x0f \x01(\tR\x12displayAspectRatio\x12\x17\n" +
+	"\apix_fmt\x18\x10 \x01(\tR\x06pixFmt\x12\x14\n" +
+	"\x05level\x18\x11 \x01(\x03R\x05level\x12 \n" +
+	"\fr_frame_rate\x18\x12 \x01(\tR\n" +
+	"rFrameRate\x12$\n" +
+	"\x0eavg_frame_rate\x18\x13 \x01(\tR\favgFrameRate\x12\x1b\n" +
+	"\ttime_base\x18\x14 \x01(\tR\btimeBase\x12\x1f\n" +
+	"\vduration_ts\x18\x15 \x01(\x03R\n" +
+	"durationTs\x12\x1a\n" +
+	"\bduration\x18\x16 \x01(\tR\bduration\x12\x19\n" +
+	"\bbit_rate\x18\x17 \x01(\tR\abitRate\"y\n" +
+	"\x0fFFProbeMetadata\x121\n" +
+	"\x06format\x18\x01 \x01(\v2\x19.indexer.v3.FFProbeFormatR\x06format\x123\n" +
+	"\astreams\x18\x02 \x03(\v2\x19.indexer.v3.FFProbeStreamR\astreams\"T\n" +
+	"\bGeometry\x12\x14\n" +
+	"\x05width\x18\x01 \x01(\x01R\x05width\x12\x16\n" +
+	"\x06height\x18\x02 \x01(\x01R\x06height\x12\f\n" +
+	"\x01x\x18\x03 \x01(\x01R\x01x\x12\f\n" +
+	"\x01y\x18\x04 \x01(\x01R\x01y\"\xef\x04\n" +
+	"\x10IdentifyMetadata\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06format\x18\x03 \x01(\tR\x06format\x12-\n" +
+	"\x12format_description\x18\x04 \x01(\tR\x11formatDescription\x12\x1b\n" +
+	"\tmime_type\x18\x05 \x01(\tR\bmimeType\x12\x14\n" +
+	"\x05class\x18\x06 \x01(\tR\x05class\x120\n" +
+	"\bgeometry\x18\a \x01(\v2\x14.indexer.v3.GeometryR\bgeometry\x12!\n" +
+	"\fresolution_x\x18\b \x01(\x01R\vresolutionX\x12!\n" +
+	"\fresolution_y\x18\t \x01(\x01R\vresolutionY\x12\x14\n" +
+	"\x05units\x18\n" +
+	" \x01(\tR\x05units\x12\x12\n" +
+	"\x04type\x18\v \x01(\tR\x04type\x12\x1e\n" +
+	"\n" +
+	"colorspace\x18\f \x01(\tR\n" +
+	"colorspace\x12\x14\n" +
+	"\x05depth\x18\r \x01(\x03R\x05depth\x12\x16\n" +
+	"\x06pixels\x18\x0e \x01(\x03R\x06pixels\x12 \n" +
+	"\vcompression\x18\x0f \x01(\tR\vcompression\x12\x18\n" +
+	"\aquality\x18\x10 \x01(\x01R\aquality\x12 \n" +
+	"\vorientation\x18\x11 \x01(\tR\vorientation\x127\n" +
+	"\n" +
+	"properties\x18\x12 \x01(\v2\x17.google.protobuf.StructR\n" +
+	"properties\x12,\n" +
+	"\x06frames\x18\x13 \x03(\v2\x14.indexer.v3.GeometryR\x06frames\"E\n" +
+	"\fTikaMetadata\x125\n" +
+	"\tdocuments\x18\x01 \x03(\v2\x17.google.protobuf.StructR\tdocuments\"\x9b\x01\n" +
+	"\x10ChecksumMetadata\x12I\n" +
+	"\tchecksums\x18\x01 \x03(\v2+.indexer.v3.ChecksumMetadata.ChecksumsEntryR\tchecksums\x1a<\n" +
+	"\x0eChecksumsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x012\x90\x01\n" +
+	"\x0eIndexerService\x127\n" +
+	"\x05Index\x12\x18.indexer.v3.IndexRequest\x1a\x12.indexer.v3.Result(\x01\x12E\n" +
+	"\vListActions\x12\x1e.indexer.v3.ListActionsRequest\x1a\x16.indexer.v3.ActionListB5Z3github.com/ocfl-archive/indexer/v3/pkg/indexerprotob\x06proto3"
+
+var (
+	file_indexer_proto_rawDescOnce sync.Once
+	file_indexer_proto_rawDescData []byte
+)
+
+func file_indexer_proto_rawDescGZIP() []byte {
+	file_indexer_proto_rawDescOnce.Do(func() {
+		file_indexer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_indexer_proto_rawDesc), len(file_indexer_proto_rawDesc)))
+	})
+	return file_indexer_proto_rawDescData
+}
+
+var file_indexer_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_indexer_proto_goTypes = []any{
+	(*IndexHeader)(nil),             // 0: indexer.v3.IndexHeader
+	(*IndexRequest)(nil),            // 1: indexer.v3.IndexRequest
+	(*ListActionsRequest)(nil),      // 2: indexer.v3.ListActionsRequest
+	(*Action)(nil),                  // 3: indexer.v3.Action
+	(*ActionList)(nil),              // 4: indexer.v3.ActionList
+	(*Result)(nil),                  // 5: indexer.v3.Result
+	(*ActionError)(nil),             // 6: indexer.v3.ActionError
+	(*Metadata)(nil),                // 7: indexer.v3.Metadata
+	(*SiegfriedIdentification)(nil), // 8: indexer.v3.SiegfriedIdentification
+	(*SiegfriedMetadata)(nil),       // 9: indexer.v3.SiegfriedMetadata
+	(*FFProbeFormat)(nil),           // 10: indexer.v3.FFProbeFormat
+	(*FFProbeStream)(nil),           // 11: indexer.v3.FFProbeStream
+	(*FFProbeMetadata)(nil),         // 12: indexer.v3.FFProbeMetadata
+	(*Geometry)(nil),                // 13: indexer.v3.Geometry
+	(*IdentifyMetadata)(nil),        // 14: indexer.v3.IdentifyMetadata
+	(*TikaMetadata)(nil),            // 15: indexer.v3.TikaMetadata
+	(*ChecksumMetadata)(nil),        // 16: indexer.v3.ChecksumMetadata
+	nil,                             // 17: indexer.v3.Result.ErrorsEntry
+	nil,                             // 18: indexer.v3.Result.ChecksumEntry
+	nil,                             // 19: indexer.v3.Result.MetadataEntry
+	nil,                             // 20: indexer.v3.FFProbeFormat.TagsEntry
+	nil,                             // 21: indexer.v3.ChecksumMetadata.ChecksumsEntry
+	(*structpb.Value)(nil),          // 22: google.protobuf.Value
+	(*structpb.Struct)(nil),         // 23: google.protobuf.Struct
+}
+var file_indexer_proto_depIdxs = []int32{
+	0,  // 0: indexer.v3.IndexRequest.header:type_name -> indexer.v3.IndexHeader
+	3,  // 1: indexer.v3.ActionList.actions:type_name -> indexer.v3.Action
+	17, // 2: indexer.v3.Result.errors:type_name -> indexer.v3.Result.ErrorsEntry
+	18, // 3: indexer.v3.Result.checksum:type_name -> indexer.v3.Result.ChecksumEntry
+	19, // 4: indexer.v3.Result.metadata:type_name -> indexer.v3.Result.MetadataEntry
+	6,  // 5: indexer.v3.Result.actionErrors:type_name -> indexer.v3.ActionError
+	9,  // 6: indexer.v3.Metadata.siegfried:type_name -> indexer.v3.SiegfriedMetadata
+	12, // 7: indexer.v3.Metadata.ffprobe:type_name -> indexer.v3.FFProbeMetadata
+	14, // 8: indexer.v3.Metadata.identify:type_name -> indexer.v3.IdentifyMetadata
+	15, // 9: indexer.v3.Metadata.tika:type_name -> indexer.v3.TikaMetadata
+	16, // 10: indexer.v3.Metadata.checksum:type_name -> indexer.v3.ChecksumMetadata
+	22, // 11: indexer.v3.Metadata.other:type_name -> google.protobuf.Value
+	8,  // 12: indexer.v3.SiegfriedMetadata.identifications:type_name -> indexer.v3.SiegfriedIdentification
+	20, // 13: indexer.v3.FFProbeFormat.tags:type_name -> indexer.v3.FFProbeFormat.TagsEntry
+	10, // 14: indexer.v3.FFProbeMetadata.format:type_name -> indexer.v3.FFProbeFormat
+	11, // 15: indexer.v3.FFProbeMetadata.streams:type_name -> indexer.v3.FFProbeStream
+	13, // 16: indexer.v3.IdentifyMetadata.geometry:type_name -> indexer.v3.Geometry
+	23, // 17: indexer.v3.IdentifyMetadata.properties:type_name -> google.protobuf.Struct
+	13, // 18: indexer.v3.IdentifyMetadata.frames:type_name -> indexer.v3.Geometry
+	23, // 19: indexer.v3.TikaMetadata.documents:type_name -> google.protobuf.Struct
+	21, // 20: indexer.v3.ChecksumMetadata.checksums:type_name -> indexer.v3.ChecksumMetadata.ChecksumsEntry
+	7,  // 21: indexer.v3.Result.MetadataEntry.value:type_name -> indexer.v3.Metadata
+	1,  // 22: indexer.v3.IndexerService.Index:input_type -> indexer.v3.IndexRequest
+	2,  // 23: indexer.v3.IndexerService.ListActions:input_type -> indexer.v3.ListActionsRequest
+	5,  // 24: indexer.v3.IndexerService.Index:output_type -> indexer.v3.Result
+	4,  // 25: indexer.v3.IndexerService.ListActions:output_type -> indexer.v3.ActionList
+	24, // [24:26] is the sub-list for method output_type
+	22, // [22:24] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
+}
+
+func init() { file_indexer_proto_init() }
+func file_indexer_proto_init() {
+	if File_indexer_proto != nil {
+		return
+	}
+	file_indexer_proto_msgTypes[1].OneofWrappers = []any{
+		(*IndexRequest_Header)(nil),
+		(*IndexRequest_Chunk)(nil),
+	}
+	file_indexer_proto_msgTypes[7].OneofWrappers = []any{
+		(*Metadata_Siegfried)(nil),
+		(*Metadata_Ffprobe)(nil),
+		(*Metadata_Identify)(nil),
+		(*Metadata_Tika)(nil),
+		(*Metadata_Checksum)(nil),
+		(*Metadata_Other)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_indexer_proto_rawDesc), len(file_indexer_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   22,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_indexer_proto_goTypes,
+		DependencyIndexes: file_indexer_proto_depIdxs,
+		MessageInfos:      file_indexer_proto_msgTypes,
+	}.Build()
+	File_indexer_proto = out.File
+	file_indexer_proto_goTypes = nil
+	file_indexer_proto_depIdxs = nil
+}
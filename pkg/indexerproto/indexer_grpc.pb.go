@@ -0,0 +1,161 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: indexer.proto
+
+package indexerproto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	IndexerService_Index_FullMethodName       = "/indexer.v3.IndexerService/Index"
+	IndexerService_ListActions_FullMethodName = "/indexer.v3.IndexerService/ListActions"
+)
+
+// IndexerServiceClient is the client API for IndexerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// IndexerService offers the indexer via grpc
+type IndexerServiceClient interface {
+	// Index receives a header with filename and actions followed by the file data in chunks
+	Index(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[IndexRequest, Result], error)
+	// ListActions returns all configured actions
+	ListActions(ctx context.Context, in *ListActionsRequest, opts ...grpc.CallOption) (*ActionList, error)
+}
+
+type indexerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIndexerServiceClient(cc grpc.ClientConnInterface) IndexerServiceClient {
+	return &indexerServiceClient{cc}
+}
+
+func (c *indexerServiceClient) Index(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[IndexRequest, Result], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &IndexerService_ServiceDesc.Streams[0], IndexerService_Index_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[IndexRequest, Result]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IndexerService_IndexClient = grpc.ClientStreamingClient[IndexRequest, Result]
+
+func (c *indexerServiceClient) ListActions(ctx context.Context, in *ListActionsRequest, opts ...grpc.CallOption) (*ActionList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ActionList)
+	err := c.cc.Invoke(ctx, IndexerService_ListActions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IndexerServiceServer is the server API for IndexerService service.
+// All implementations must embed UnimplementedIndexerServiceServer
+// for forward compatibility.
+//
+// IndexerService offers the indexer via grpc
+type IndexerServiceServer interface {
+	// Index receives a header with filename and actions followed by the file data in chunks
+	Index(grpc.ClientStreamingServer[IndexRequest, Result]) error
+	// ListActions returns all configured actions
+	ListActions(context.Context, *ListActionsRequest) (*ActionList, error)
+	mustEmbedUnimplementedIndexerServiceServer()
+}
+
+// UnimplementedIndexerServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedIndexerServiceServer struct{}
+
+func (UnimplementedIndexerServiceServer) Index(grpc.ClientStreamingServer[IndexRequest, Result]) error {
+	return status.Errorf(codes.Unimplemented, "method Index not implemented")
+}
+func (UnimplementedIndexerServiceServer) ListActions(context.Context, *ListActionsRequest) (*ActionList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListActions not implemented")
+}
+func (UnimplementedIndexerServiceServer) mustEmbedUnimplementedIndexerServiceServer() {}
+func (UnimplementedIndexerServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeIndexerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IndexerServiceServer will
+// result in compilation errors.
+type UnsafeIndexerServiceServer interface {
+	mustEmbedUnimplementedIndexerServiceServer()
+}
+
+func RegisterIndexerServiceServer(s grpc.ServiceRegistrar, srv IndexerServiceServer) {
+	// If the following call pancis, it indicates UnimplementedIndexerServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&IndexerService_ServiceDesc, srv)
+}
+
+func _IndexerService_Index_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IndexerServiceServer).Index(&grpc.GenericServerStream[IndexRequest, Result]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IndexerService_IndexServer = grpc.ClientStreamingServer[IndexRequest, Result]
+
+func _IndexerService_ListActions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListActionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServiceServer).ListActions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IndexerService_ListActions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServiceServer).ListActions(ctx, req.(*ListActionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// IndexerService_ServiceDesc is the grpc.ServiceDesc for IndexerService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IndexerService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "indexer.v3.IndexerService",
+	HandlerType: (*IndexerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListActions",
+			Handler:    _IndexerService_ListActions_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Index",
+			Handler:       _IndexerService_Index_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "indexer.proto",
+}
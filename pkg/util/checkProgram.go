@@ -15,6 +15,8 @@ const CheckProgramFFProbe = "ffprobe"
 const CheckProgramFFMpeg = "ffmpeg"
 const CheckProgramTika = "tika"
 const CheckProgramGhostscript = "ghostscript"
+const CheckProgramJHOVE = "jhove"
+const CheckProgramVeraPDF = "verapdf"
 
 type checkProgramStruct struct {
 	Name   []string
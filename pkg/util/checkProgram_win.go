@@ -32,4 +32,14 @@ var checkProgramList = map[string]checkProgramStruct{
 		Param:  []string{"-version"},
 		Result: regexp.MustCompile("^ffmpeg version "),
 	},
+	CheckProgramJHOVE: {
+		Name:   []string{"jhove.bat"},
+		Param:  []string{"-v"},
+		Result: regexp.MustCompile(`^Jhove \(Rel\. `),
+	},
+	CheckProgramVeraPDF: {
+		Name:   []string{"verapdf.bat"},
+		Param:  []string{"--version"},
+		Result: regexp.MustCompile("^veraPDF "),
+	},
 }
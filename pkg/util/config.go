@@ -93,6 +93,26 @@ func OptimizeConfig(conf *indexer.IndexerConfig, logger zLogger.ZLogger) (config
 		miniConfig["imagemagick.convert"] = conf.ImageMagick.Convert
 		miniConfig["imagemagick.enabled"] = conf.ImageMagick.Enabled
 	}
+	if conf.JHOVE.Enabled {
+		if jhovepath, ok := CheckProgram(CheckProgramJHOVE, conf.JHOVE.JHOVE); ok {
+			conf.JHOVE.JHOVE = jhovepath
+		} else {
+			conf.JHOVE.Enabled = false
+			logger.Info().Msg("JHOVE disabled")
+		}
+		miniConfig["jhove.enabled"] = conf.JHOVE.Enabled
+		miniConfig["jhove.jhove"] = conf.JHOVE.JHOVE
+	}
+	if conf.VeraPDF.Enabled {
+		if verapdfpath, ok := CheckProgram(CheckProgramVeraPDF, conf.VeraPDF.VeraPDF); ok {
+			conf.VeraPDF.VeraPDF = verapdfpath
+		} else {
+			conf.VeraPDF.Enabled = false
+			logger.Info().Msg("veraPDF disabled")
+		}
+		miniConfig["verapdf.enabled"] = conf.VeraPDF.Enabled
+		miniConfig["verapdf.verapdf"] = conf.VeraPDF.VeraPDF
+	}
 	if conf.Tika.Enabled {
 		tikaoptimize := func() error {
 			if conf.Tika.AddressMeta == "" {
@@ -0,0 +1,271 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/je4/utils/v2/pkg/zLogger"
+	"github.com/ocfl-archive/indexer/v3/pkg/indexer"
+	sfconfig "github.com/richardlehane/siegfried/pkg/config"
+)
+
+// toolVersion returns the output of the version call of a tool or an empty string
+func toolVersion(tool string, wsl bool, params ...string) string {
+	if tool == "" {
+		return ""
+	}
+	parts := strings.Fields(tool)
+	if wsl {
+		parts = append([]string{"wsl"}, parts...)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, parts[0], append(parts[1:], params...)...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return ""
+	}
+	return out.String()
+}
+
+// fileStamp returns name, size and modification time of a file or of all files of a folder
+func fileStamp(name string) (string, error) {
+	var stamp strings.Builder
+	if err := filepath.WalkDir(name, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&stamp, "%s %d %d\n", filepath.ToSlash(path), info.Size(), info.ModTime().UnixNano())
+		return nil
+	}); err != nil {
+		return "", errors.Wrapf(err, "cannot stat %s", name)
+	}
+	return stamp.String(), nil
+}
+
+// httpVersion returns the beginning of the response of a version endpoint
+func httpVersion(address string) (string, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(address)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot query %s", address)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("cannot query %s: %s", address, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot read %s", address)
+	}
+	return string(data), nil
+}
+
+// tikaVersion returns the version of the tika server at address
+func tikaVersion(address string) (string, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot parse tika address %s", address)
+	}
+	u.Path = "/version"
+	u.RawQuery = ""
+	return httpVersion(u.String())
+}
+
+// commandVersion returns the version output of a command action or the stamp of its executable
+func commandVersion(c indexer.ConfigCommandAction) (string, error) {
+	if len(c.VersionArgs) > 0 {
+		return toolVersion(c.Command, c.Wsl, c.VersionArgs...), nil
+	}
+	if c.Wsl {
+		return "", nil
+	}
+	executable, err := exec.LookPath(c.Command)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot find %s", c.Command)
+	}
+	return fileStamp(executable)
+}
+
+// Fingerprint identifies the indexer version and configuration, the siegfried signature, the NSRL data
+// and the versions of the external tools and services. Cached results of a different fingerprint are not used.
+// Versions, which are not available, are logged and left out. The signatures of ClamAV can change while running,
+// they are part of CacheVolatile.
+func Fingerprint(conf *indexer.IndexerConfig, signature []byte, logger zLogger.ZLogger) (string, error) {
+	c := *conf
+	c.Cache = indexer.ConfigCache{}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot marshal config")
+	}
+	h := sha256.New()
+	h.Write([]byte(indexerVersion()))
+	h.Write(data)
+	h.Write(signature)
+	if conf.NSRL.Enabled {
+		for _, name := range []string{conf.NSRL.Badger, conf.NSRL.Filter} {
+			if name == "" {
+				continue
+			}
+			stamp, err := fileStamp(name)
+			if err != nil {
+				return "", errors.Wrap(err, "cannot stamp NSRL data")
+			}
+			h.Write([]byte(stamp))
+		}
+	}
+	if conf.FFMPEG.Enabled {
+		h.Write([]byte(toolVersion(conf.FFMPEG.FFProbe, conf.FFMPEG.Wsl, "-version")))
+	}
+	if conf.ImageMagick.Enabled {
+		h.Write([]byte(toolVersion(conf.ImageMagick.Identify, conf.ImageMagick.Wsl, "-version")))
+	}
+	if conf.JHOVE.Enabled {
+		h.Write([]byte(toolVersion(conf.JHOVE.JHOVE, conf.JHOVE.Wsl, "-v")))
+	}
+	if conf.VeraPDF.Enabled {
+		h.Write([]byte(toolVersion(conf.VeraPDF.VeraPDF, conf.VeraPDF.Wsl, "--version")))
+	}
+	if conf.Tika.Enabled {
+		for _, address := range []string{conf.Tika.AddressMeta, conf.Tika.AddressFulltext} {
+			if address == "" {
+				continue
+			}
+			version, err := tikaVersion(address)
+			if err != nil {
+				logger.Warn().Err(err).Msg("tika version not part of the cache fingerprint")
+			}
+			h.Write([]byte(version))
+		}
+	}
+	for _, ext := range conf.External {
+		if ext.VersionAddress == "" {
+			continue
+		}
+		version, err := httpVersion(ext.VersionAddress)
+		if err != nil {
+			logger.Warn().Err(err).Msgf("version of external action '%s' not part of the cache fingerprint", ext.Name)
+		}
+		h.Write([]byte(version))
+	}
+	for _, cmd := range conf.Command {
+		version, err := commandVersion(cmd)
+		if err != nil {
+			logger.Warn().Err(err).Msgf("version of command action '%s' not part of the cache fingerprint", cmd.Name)
+		}
+		h.Write([]byte(version))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// CacheVolatile returns the part of the cache fingerprint, which is read for every lookup: the signature version of ClamAV.
+// If the version is not available, the result is not cached.
+func CacheVolatile(conf *indexer.IndexerConfig, logger zLogger.ZLogger) func(actions []string) (string, bool) {
+	return func(actions []string) (string, bool) {
+		if !conf.Clamav.Enabled || !slices.Contains(actions, indexer.NameClamav) {
+			return "", true
+		}
+		version, err := clamavVersion(conf)
+		if err == nil && version == "" {
+			err = errors.New("empty version")
+		}
+		if err != nil {
+			logger.Warn().Err(err).Msg("clamav version not available, result is not cached")
+			return "", false
+		}
+		return version, true
+	}
+}
+
+// clamavVersion returns the version of clamd or clamscan including the signature version
+func clamavVersion(conf *indexer.IndexerConfig) (string, error) {
+	if conf.Clamav.Address == "" {
+		return toolVersion(conf.Clamav.ClamScan, conf.Clamav.Wsl, "--version"), nil
+	}
+	clamd, err := indexer.NewClamdClient(conf.Clamav.Address, time.Duration(conf.Clamav.Timeout), conf.Clamav.StreamMaxLength)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot create clamd client")
+	}
+	version, err := clamd.Version()
+	if err != nil {
+		return "", errors.Wrap(err, "cannot get clamd version")
+	}
+	return version, nil
+}
+
+// indexerVersion returns the module version of the indexer or an empty string, if unknown
+func indexerVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	const path = "github.com/ocfl-archive/indexer/v3"
+	if info.Main.Path == path {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == path {
+			return dep.Version
+		}
+	}
+	return ""
+}
+
+// ToolVersions returns the versions of the enabled actions, the internal actions get the version of the indexer
+func ToolVersions(conf *indexer.IndexerConfig) map[string]string {
+	firstLine := func(str string) string {
+		line, _, _ := strings.Cut(strings.TrimSpace(str), "\n")
+		return strings.TrimSpace(line)
+	}
+	version := indexerVersion()
+	versions := map[string]string{
+		indexer.NameChecksum:  version,
+		indexer.NameXML:       version,
+		indexer.NameJSON:      version,
+		indexer.NameContainer: version,
+		indexer.NameNSRL:      version,
+	}
+	sf := sfconfig.Version()
+	versions[indexer.NameSiegfried] = fmt.Sprintf("%d.%d.%d", sf[0], sf[1], sf[2])
+	if conf.FFMPEG.Enabled {
+		versions[indexer.NameFFProbe] = firstLine(toolVersion(conf.FFMPEG.FFProbe, conf.FFMPEG.Wsl, "-version"))
+	}
+	if conf.ImageMagick.Enabled {
+		versions[indexer.NameIdentify] = firstLine(toolVersion(conf.ImageMagick.Identify, conf.ImageMagick.Wsl, "-version"))
+	}
+	if conf.JHOVE.Enabled {
+		versions[indexer.NameJHOVE] = firstLine(toolVersion(conf.JHOVE.JHOVE, conf.JHOVE.Wsl, "-v"))
+	}
+	if conf.VeraPDF.Enabled {
+		versions[indexer.NameVeraPDF] = firstLine(toolVersion(conf.VeraPDF.VeraPDF, conf.VeraPDF.Wsl, "--version"))
+	}
+	if conf.Clamav.Enabled {
+		version, _ := clamavVersion(conf)
+		versions[indexer.NameClamav] = firstLine(version)
+	}
+	return versions
+}
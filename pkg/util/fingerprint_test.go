@@ -0,0 +1,139 @@
+package util
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ocfl-archive/indexer/v3/pkg/indexer"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClamdVersion answers the VERSION command with the current value of version
+func fakeClamdVersion(t *testing.T, version *atomic.Value) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("cannot listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			cmd, _ := bufio.NewReader(conn).ReadString('\x00')
+			if cmd == "zVERSION\x00" {
+				conn.Write([]byte(version.Load().(string) + "\x00"))
+			}
+			conn.Close()
+		}
+	}()
+	return "tcp://" + listener.Addr().String()
+}
+
+func TestFingerprint(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script as ffprobe")
+	}
+	logger := zerolog.Nop()
+	folder := t.TempDir()
+	ffprobe := filepath.Join(folder, "ffprobe")
+	writeFFProbe := func(version string) {
+		assert.NoError(t, os.WriteFile(ffprobe, []byte("#!/bin/sh\necho \"ffprobe version "+version+"\"\n"), 0755))
+	}
+	writeFFProbe("6.0")
+	filter := filepath.Join(folder, "nsrl.filter")
+	assert.NoError(t, os.WriteFile(filter, []byte("filter 1"), 0644))
+	var serviceVersion atomic.Value
+	serviceVersion.Store("Apache Tika 2.9.0")
+	service := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(serviceVersion.Load().(string)))
+	}))
+	defer service.Close()
+	command := filepath.Join(folder, "tool")
+	writeCommand := func(version string) {
+		assert.NoError(t, os.WriteFile(command, []byte("#!/bin/sh\necho \""+version+"\"\n"), 0755))
+	}
+	writeCommand("tool 1.0")
+
+	conf := &indexer.IndexerConfig{
+		FFMPEG:   indexer.ConfigFFMPEG{Enabled: true, FFProbe: ffprobe},
+		NSRL:     indexer.ConfigNSRL{Enabled: true, Filter: filter},
+		Tika:     indexer.ConfigTika{Enabled: true, AddressMeta: service.URL + "/meta"},
+		External: []indexer.ConfigExternalAction{{Name: "ext", Address: service.URL + "/ext/[[PATH]]", VersionAddress: service.URL + "/version"}},
+		Command:  []indexer.ConfigCommandAction{{Name: "tool", Command: command, VersionArgs: []string{"-v"}}},
+	}
+	signature := []byte("signature 1")
+	key, err := Fingerprint(conf, signature, &logger)
+	if !assert.NoError(t, err) {
+		return
+	}
+	same, err := Fingerprint(conf, signature, &logger)
+	assert.NoError(t, err)
+	assert.Equal(t, key, same)
+
+	changes := []struct {
+		name   string
+		change func()
+	}{
+		{name: "signature", change: func() { signature = []byte("signature 2") }},
+		{name: "tool version", change: func() { writeFFProbe("7.0") }},
+		{name: "service version", change: func() { serviceVersion.Store("Apache Tika 3.0.0") }},
+		{name: "command version", change: func() { writeCommand("tool 2.0") }},
+		{name: "command config", change: func() { conf.Command[0].Args = []string{"[[PATH]]"} }},
+		{name: "nsrl filter", change: func() {
+			assert.NoError(t, os.WriteFile(filter, []byte("filter 2"), 0644))
+			assert.NoError(t, os.Chtimes(filter, time.Now(), time.Now().Add(time.Hour)))
+		}},
+		// versions, which are not available, are left out
+		{name: "service down", change: service.Close},
+	}
+	for _, tt := range changes {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.change()
+			changed, err := Fingerprint(conf, signature, &logger)
+			assert.NoError(t, err)
+			assert.NotEqual(t, key, changed)
+			key = changed
+		})
+	}
+}
+
+func TestCacheVolatile(t *testing.T) {
+	logger := zerolog.Nop()
+	var clamdVersion atomic.Value
+	clamdVersion.Store("ClamAV 1.0.0/27000/Mon Jan 1 00:00:00 2024")
+	conf := &indexer.IndexerConfig{
+		Clamav: indexer.ConfigClamAV{Enabled: true, Address: fakeClamdVersion(t, &clamdVersion)},
+	}
+	volatile := CacheVolatile(conf, &logger)
+	version, ok := volatile([]string{indexer.NameChecksum})
+	assert.True(t, ok)
+	assert.Empty(t, version)
+
+	version, ok = volatile([]string{indexer.NameChecksum, indexer.NameClamav})
+	assert.True(t, ok)
+	// the signature update of clamd is recognized without restart
+	clamdVersion.Store("ClamAV 1.0.0/27001/Tue Jan 2 00:00:00 2024")
+	updated, ok := volatile([]string{indexer.NameClamav})
+	assert.True(t, ok)
+	assert.NotEqual(t, version, updated)
+
+	// without clamd, the result is not cached
+	conf.Clamav.Address = "tcp://127.0.0.1:1"
+	_, ok = volatile([]string{indexer.NameClamav})
+	assert.False(t, ok)
+}
@@ -3,6 +3,7 @@ package util
 import (
 	"io"
 	"io/fs"
+	"net/http"
 
 	"emperror.dev/errors"
 	"github.com/je4/utils/v2/pkg/checksum"
@@ -16,6 +17,15 @@ func (idx *Indexer) ActionDispatcher() *indexer.ActionDispatcher {
 	return (*indexer.ActionDispatcher)(idx)
 }
 
+// MetricsHandler returns the handler of the prometheus metrics or nil, if metrics are disabled
+func (idx *Indexer) MetricsHandler() http.Handler {
+	metrics := idx.ActionDispatcher().Metrics()
+	if metrics == nil {
+		return nil
+	}
+	return metrics.Handler()
+}
+
 func (idx *Indexer) Index(fsys fs.FS, path string, realname string, actions []string, digestAlgs []checksum.DigestAlgorithm, writer io.Writer, logger zLogger.ZLogger) (*indexer.ResultV2, map[checksum.DigestAlgorithm]string, error) {
 	if realname == "" {
 		realname = path
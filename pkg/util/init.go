@@ -11,17 +11,19 @@ import (
 	"github.com/je4/utils/v2/pkg/zLogger"
 	datasiegfried "github.com/ocfl-archive/indexer/v3/data/siegfried"
 	"github.com/ocfl-archive/indexer/v3/pkg/indexer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
 type _closer []io.Closer
 
-func (c _closer) AddCloser(closer io.Closer) {
-	c = append(c, closer)
+func (c *_closer) AddCloser(closer io.Closer) {
+	*c = append(*c, closer)
 }
 
-func (c _closer) Close() error {
+func (c *_closer) Close() error {
 	var errs = []error{}
-	for _, closer := range c {
+	for _, closer := range *c {
 		if err := closer.Close(); err != nil {
 			errs = append(errs, err)
 		}
@@ -34,7 +36,7 @@ func (c _closer) Close() error {
 // the actions are named NameSiegfried, NameIdentify, NameFFProbe, NameTika and NameFullText
 func InitIndexer(conf *indexer.IndexerConfig, logger zLogger.ZLogger) (ad *Indexer, actions []string, closer io.Closer, err error) {
 	actions = []string{}
-	closerList := _closer{}
+	closerList := &_closer{}
 	closer = closerList
 	var relevance = map[int]indexer.MimeWeightString{}
 
@@ -57,6 +59,18 @@ func InitIndexer(conf *indexer.IndexerConfig, logger zLogger.ZLogger) (ad *Index
 	}
 
 	ad = (*Indexer)(indexer.NewActionDispatcher(relevance))
+	ad.ActionDispatcher().SetTempDir(conf.TempDir)
+	ad.ActionDispatcher().SetLocalCache(conf.LocalCache, conf.MaxDownloadSize)
+	ad.ActionDispatcher().SetHead(conf.HeaderSize, conf.TailSize, time.Duration(conf.HeaderTimeout))
+	if conf.Metrics.Enabled {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+		metrics, err := indexer.NewMetrics(registry)
+		if err != nil {
+			return nil, nil, nil, errors.WithStack(err)
+		}
+		ad.ActionDispatcher().SetMetrics(metrics)
+	}
 	var signature []byte
 	if conf.Siegfried.Enabled {
 		if conf.Siegfried.SignatureFile == "" || conf.Siegfried.SignatureFile == "internal" {
@@ -94,6 +108,16 @@ func InitIndexer(conf *indexer.IndexerConfig, logger zLogger.ZLogger) (ad *Index
 		logger.Info().Msg("indexer action identify added")
 		actions = append(actions, indexer.NameIdentify)
 	}
+	if conf.JHOVE.Enabled {
+		_ = indexer.NewActionJHOVE(indexer.NameJHOVE, conf.JHOVE.JHOVE, conf.JHOVE.Config, conf.JHOVE.Wsl, time.Duration(conf.JHOVE.Timeout), conf.TempDir, ad.ActionDispatcher())
+		logger.Info().Msg("indexer action jhove added")
+		actions = append(actions, indexer.NameJHOVE)
+	}
+	if conf.VeraPDF.Enabled {
+		_ = indexer.NewActionVeraPDF(indexer.NameVeraPDF, conf.VeraPDF.VeraPDF, conf.VeraPDF.Flavour, conf.VeraPDF.Wsl, time.Duration(conf.VeraPDF.Timeout), conf.TempDir, ad.ActionDispatcher())
+		logger.Info().Msg("indexer action verapdf added")
+		actions = append(actions, indexer.NameVeraPDF)
+	}
 	if conf.Tika.Enabled {
 		if conf.Tika.AddressMeta != "" {
 			_ = indexer.NewActionTika(indexer.NameTika, conf.Tika.AddressMeta, time.Duration(conf.Tika.Timeout), conf.Tika.RegexpMimeMeta, conf.Tika.RegexpMimeMetaNot, "", conf.Tika.Online, ad.ActionDispatcher())
@@ -114,13 +138,25 @@ func InitIndexer(conf *indexer.IndexerConfig, logger zLogger.ZLogger) (ad *Index
 	}
 
 	if conf.Clamav.Enabled {
-		indexer.NewActionClamAV(indexer.NameClamav, conf.Clamav.ClamScan, conf.Clamav.Wsl, time.Duration(conf.Clamav.Timeout), ad.ActionDispatcher())
+		var clamd *indexer.ClamdClient
+		if conf.Clamav.Address != "" {
+			clamd, err = indexer.NewClamdClient(conf.Clamav.Address, time.Duration(conf.Clamav.Timeout), conf.Clamav.StreamMaxLength)
+			if err != nil {
+				closer.Close()
+				return nil, nil, nil, errors.Wrapf(err, "cannot create clamd client for %s", conf.Clamav.Address)
+			}
+			if err := clamd.Ping(); err != nil {
+				logger.Error().Err(err).Msgf("clamd at %s not reachable", conf.Clamav.Address)
+			}
+		}
+		indexer.NewActionClamAV(indexer.NameClamav, conf.Clamav.ClamScan, clamd, conf.Clamav.Wsl, time.Duration(conf.Clamav.Timeout), conf.TempDir, ad.ActionDispatcher())
+		logger.Info().Msg("indexer action clamav added")
 		actions = append(actions, indexer.NameClamav)
 	}
 
 	if conf.NSRL.Enabled {
-		var nsrldb *badger.DB
-		if conf.NSRL.Enabled {
+		var store indexer.NSRLStore
+		if conf.NSRL.Badger != "" {
 			stat2, err := os.Stat(conf.NSRL.Badger)
 			if err != nil {
 				closer.Close()
@@ -128,27 +164,39 @@ func InitIndexer(conf *indexer.IndexerConfig, logger zLogger.ZLogger) (ad *Index
 			}
 			if !stat2.IsDir() {
 				closer.Close()
-				return nil, nil, nil, errors.Wrapf(err, "%s is not a directory", conf.NSRL.Badger)
+				return nil, nil, nil, errors.Errorf("%s is not a directory", conf.NSRL.Badger)
 			}
 
 			bconfig := badger.DefaultOptions(conf.NSRL.Badger)
 			bconfig.ReadOnly = true
-			nsrldb, err = badger.Open(bconfig)
+			nsrldb, err := badger.Open(bconfig)
 			if err != nil {
 				closer.Close()
 				return nil, nil, nil, errors.Wrapf(err, "cannot open NSRL badger %s", conf.NSRL.Badger)
 			}
-			//log.Infof("nsrl max batch count: %v", nsrldb.MaxBatchCount())
-			//			defer nsrldb.Close()
 			var keyCount uint32
 			for _, tbl := range nsrldb.Tables() {
 				keyCount += tbl.KeyCount
 			}
 			closerList.AddCloser(nsrldb)
 			logger.Info().Msgf("NSRL-Table: %v keys", keyCount)
-			indexer.NewActionNSRL(indexer.NameNSRL, nsrldb, ad.ActionDispatcher(), logger)
-			actions = append(actions, indexer.NameNSRL)
+			store = indexer.NewNSRLBadgerStore(nsrldb)
+		}
+		if conf.NSRL.Filter != "" {
+			filter, err := indexer.LoadNSRLFilter(conf.NSRL.Filter)
+			if err != nil {
+				closer.Close()
+				return nil, nil, nil, errors.Wrapf(err, "cannot load NSRL filter %s", conf.NSRL.Filter)
+			}
+			logger.Info().Msgf("NSRL-Filter: %v keys", filter.Count())
+			store = indexer.NewNSRLFilterStore(filter, store)
 		}
+		if store == nil {
+			closer.Close()
+			return nil, nil, nil, errors.New("NSRL needs badger or filter")
+		}
+		indexer.NewActionNSRL(indexer.NameNSRL, store, ad.ActionDispatcher(), logger)
+		actions = append(actions, indexer.NameNSRL)
 	}
 
 	for _, eaconfig := range conf.External {
@@ -156,9 +204,57 @@ func InitIndexer(conf *indexer.IndexerConfig, logger zLogger.ZLogger) (ad *Index
 		for _, c := range eaconfig.ActionCapabilities {
 			caps |= uint(c)
 		}
-		indexer.NewActionExternal(eaconfig.Name, eaconfig.Address, indexer.ActionCapability(caps), eaconfig.CallType, eaconfig.Mimetype, ad.ActionDispatcher())
+		if _, err := indexer.NewActionExternal(eaconfig.Name, eaconfig.Address, indexer.ActionCapability(caps), eaconfig.CallType, eaconfig.Mimetype, eaconfig.Mapping, time.Duration(eaconfig.Timeout), ad.ActionDispatcher()); err != nil {
+			closer.Close()
+			return nil, nil, nil, errors.Wrapf(err, "cannot create external action %s", eaconfig.Name)
+		}
 		actions = append(actions, eaconfig.Name)
 	}
 
+	for _, caconfig := range conf.Command {
+		if _, err := indexer.NewActionCommand(caconfig.Name, caconfig.Command, caconfig.Args, caconfig.Wsl, caconfig.Input, caconfig.Output, caconfig.Separator, caconfig.Regexp, caconfig.Mapping, caconfig.Mimetype, caconfig.Weight, time.Duration(caconfig.Timeout), conf.TempDir, ad.ActionDispatcher()); err != nil {
+			closer.Close()
+			return nil, nil, nil, errors.Wrapf(err, "cannot create command action %s", caconfig.Name)
+		}
+		logger.Info().Msgf("indexer action %s added", caconfig.Name)
+		actions = append(actions, caconfig.Name)
+	}
+
+	if conf.Container.Enabled {
+		indexer.NewActionContainer(indexer.NameContainer, conf.Container.Actions, conf.Container.MaxDepth, conf.Container.MaxMembers, conf.Container.MaxSize, conf.TempDir, ad.ActionDispatcher())
+		logger.Info().Msg("indexer action container added")
+		actions = append(actions, indexer.NameContainer)
+	}
+
+	if conf.Cache.Enabled {
+		var cache indexer.ResultCache
+		switch {
+		case conf.Cache.Badger != "":
+			cachedb, err := badger.Open(badger.DefaultOptions(conf.Cache.Badger).WithLogger(nil))
+			if err != nil {
+				closer.Close()
+				return nil, nil, nil, errors.Wrapf(err, "cannot open cache badger %s", conf.Cache.Badger)
+			}
+			closerList.AddCloser(cachedb)
+			cache = indexer.NewResultCacheBadger(cachedb, time.Duration(conf.Cache.TTL))
+		case conf.Cache.Dir != "":
+			if cache, err = indexer.NewResultCacheDir(conf.Cache.Dir); err != nil {
+				closer.Close()
+				return nil, nil, nil, errors.WithStack(err)
+			}
+		default:
+			closer.Close()
+			return nil, nil, nil, errors.New("cache needs badger or dir")
+		}
+		fingerprint, err := Fingerprint(conf, signature, logger)
+		if err != nil {
+			closer.Close()
+			return nil, nil, nil, errors.Wrap(err, "cannot create cache fingerprint")
+		}
+		ad.ActionDispatcher().SetCache(cache, fingerprint, conf.TempDir)
+		ad.ActionDispatcher().SetCacheVolatile(CacheVolatile(conf, logger))
+		logger.Info().Msgf("result cache enabled with fingerprint %s", fingerprint)
+	}
+
 	return
 }
@@ -0,0 +1,29 @@
+package util
+
+import (
+	"io"
+	"net"
+	"net/http"
+
+	"emperror.dev/errors"
+	"github.com/je4/utils/v2/pkg/zLogger"
+)
+
+// ServeMetrics starts an unauthenticated metrics endpoint at addr/metrics for the batch commands.
+// The address is bound before returning, so a bind failure is returned as error.
+func ServeMetrics(addr string, handler http.Handler, logger zLogger.ZLogger) (io.Closer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot listen on %s", addr)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", handler)
+	server := &http.Server{Handler: mux}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error().Err(err).Msgf("metrics endpoint at %s failed", addr)
+		}
+	}()
+	logger.Info().Msgf("metrics endpoint at http://%s/metrics", listener.Addr())
+	return server, nil
+}
@@ -0,0 +1,54 @@
+package util
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ocfl-archive/indexer/v3/pkg/indexer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeMetrics(t *testing.T) {
+	logger := zerolog.Nop()
+	ad := indexer.NewActionDispatcher(nil)
+	assert.Nil(t, (*Indexer)(ad).MetricsHandler())
+	metrics, err := indexer.NewMetrics(prometheus.NewRegistry())
+	if !assert.NoError(t, err) {
+		return
+	}
+	ad.SetMetrics(metrics)
+	indexer.NewActionChecksum(indexer.NameChecksum, nil, ad)
+	_, err = ad.Stream(strings.NewReader("plain text"), []string{"test.txt"}, []string{indexer.NameChecksum})
+	assert.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer listener.Close()
+	// the address is in use
+	_, err = ServeMetrics(listener.Addr().String(), (*Indexer)(ad).MetricsHandler(), &logger)
+	assert.Error(t, err)
+
+	addr := listener.Addr().String()
+	listener.Close()
+	closer, err := ServeMetrics(addr, (*Indexer)(ad).MetricsHandler(), &logger)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer closer.Close()
+	resp, err := http.Get("http://" + addr + "/metrics")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), "indexer_action_duration_seconds")
+}